@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log/slog"
 	"net"
@@ -13,7 +14,9 @@ import (
 
 	"github.com/kias-hack/web-watcher/internal/bootstrap"
 	"github.com/kias-hack/web-watcher/internal/config"
-	httpcheck "github.com/kias-hack/web-watcher/internal/infra/httpheck"
+	"github.com/kias-hack/web-watcher/internal/delivery"
+	"github.com/kias-hack/web-watcher/internal/infra/metrics"
+	"github.com/kias-hack/web-watcher/internal/infra/web"
 	"github.com/kias-hack/web-watcher/internal/watchdog"
 )
 
@@ -41,22 +44,70 @@ func main() {
 
 	ctx := context.Background()
 
-	ruleNotifier, err := bootstrap.MapConfigNotifierToDomainRoutedNotifier(*config)
+	// метрики и страница состояния могут слушать один адрес, поэтому обработчики собираются по адресам
+	muxes := make(map[string]*http.ServeMux)
+	muxFor := func(addr string) *http.ServeMux {
+		if _, ok := muxes[addr]; !ok {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+
+	var deliveryMetrics delivery.Metrics
+	var watchdogMetrics watchdog.Metrics
+	if config.Metrics.Listen != "" {
+		prom := metrics.NewPrometheus()
+		deliveryMetrics = prom
+		watchdogMetrics = prom
+
+		muxFor(config.Metrics.Listen).Handle("/metrics", prom.Handler())
+	}
+
+	ruleNotifier, err := bootstrap.MapConfigNotifierToDomainRoutedNotifier(*config, deliveryMetrics)
 	if err != nil {
 		slog.Error("failed create notification rules", "err", err)
 		os.Exit(1)
 	}
 
-	httpClient := &http.Client{
-		Timeout: config.HTTP.Timeout,
+	dispatcher, err := bootstrap.CreateDispatcher(*config, ruleNotifier, deliveryMetrics)
+	if err != nil {
+		slog.Error("failed create notification dispatcher", "err", err)
+		os.Exit(1)
 	}
-	if len(config.HTTP.DNSResolvers) > 0 {
-		httpClient = newHTTPClientWithDNS(config.HTTP.DNSResolvers, config.HTTP.Timeout)
+
+	stateStore, err := bootstrap.CreateStateStore(*config)
+	if err != nil {
+		slog.Error("failed create state store", "err", err)
+		os.Exit(1)
 	}
 
-	watchdog := watchdog.NewWatchdog(bootstrap.MapConfigServiceToDomainService(config.Services), httpcheck.NewChecker(httpClient), ruleNotifier)
+	resultStore, err := bootstrap.CreateResultStore(*config)
+	if err != nil {
+		slog.Error("failed create result store", "err", err)
+		os.Exit(1)
+	}
+
+	watchdog := watchdog.NewWatchdog(bootstrap.MapConfigServiceToDomainService(config.Services, bootstrap.CreateTLSProber(*config), bootstrap.CreateDNSResolver(*config)), bootstrap.CreateServiceChecker(*config), ruleNotifier, dispatcher, stateStore, resultStore, watchdogMetrics)
 
-	watchdog.Start()
+	if config.Status.Listen != "" {
+		muxFor(config.Status.Listen).Handle("/", web.NewStatusHandler(watchdog, resultStore))
+	}
+
+	var servers []*http.Server
+	for addr, mux := range muxes {
+		server, err := startHTTPServer(addr, mux)
+		if err != nil {
+			slog.Error("failed start http server", "addr", addr, "err", err)
+			os.Exit(1)
+		}
+
+		servers = append(servers, server)
+	}
+
+	if err := watchdog.Start(); err != nil {
+		slog.Error("failed start watchdog", "err", err)
+		os.Exit(1)
+	}
 
 	slog.Info("service started")
 
@@ -74,36 +125,34 @@ func main() {
 		slog.Error("got error while stoping service", "err", err)
 	}
 
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("got error while stopping http server", "addr", server.Addr, "err", err)
+		}
+	}
+
 	slog.Info("Bye!")
 }
 
-func newHTTPClientWithDNS(dnsAddrs []string, timeout time.Duration) *http.Client {
-	resolver := &net.Resolver{
-		PreferGo: true, // важно: использовать Go-resolver, чтобы сработал кастомный Dial
-		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{Timeout: 3 * time.Second}
-			var lastErr error
-			for _, dnsAddr := range dnsAddrs {
-				conn, err := d.DialContext(ctx, "udp", dnsAddr)
-				if err == nil {
-					return conn, nil
-				}
-				lastErr = err
-			}
-
-			return nil, lastErr
-		},
-	}
-	dialer := &net.Dialer{
-		Timeout:  5 * time.Second,
-		Resolver: resolver,
-	}
-	tr := &http.Transport{
-		DialContext:         dialer.DialContext,
-		TLSHandshakeTimeout: 5 * time.Second,
+// startHTTPServer занимает адрес сразу, чтобы занятый порт останавливал запуск, а не только писал в лог.
+func startHTTPServer(addr string, handler http.Handler) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
 	}
-	return &http.Client{
-		Transport: tr,
-		Timeout:   timeout,
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
 	}
+
+	go func() {
+		slog.Info("http server started", "addr", addr)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("http server stopped", "addr", addr, "err", err)
+		}
+	}()
+
+	return server, nil
 }
@@ -0,0 +1,100 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/kias-hack/web-watcher/internal/infra/dnscheck"
+	httpcheck "github.com/kias-hack/web-watcher/internal/infra/httpheck"
+	"github.com/kias-hack/web-watcher/internal/infra/tcpcheck"
+	"github.com/kias-hack/web-watcher/internal/infra/tlscheck"
+)
+
+// CreateServiceChecker проверки для всех типов сервисов, резолверы и таймаут из [http] общие.
+func CreateServiceChecker(cfg config.AppConfig) domain.ServiceChecker {
+	dialer := newDialer(cfg.HTTP.DNSResolvers)
+
+	skipVerifyClient := newHTTPClient(cfg.HTTP, dialer)
+	skipTLSVerify(skipVerifyClient)
+
+	return domain.NewCheckerRouter(map[string]domain.ServiceChecker{
+		config.SERVICE_TYPE_HTTP: httpcheck.NewChecker(newHTTPClient(cfg.HTTP, dialer), skipVerifyClient),
+		config.SERVICE_TYPE_TLS:  tlscheck.NewChecker(dialer, cfg.HTTP.Timeout),
+		config.SERVICE_TYPE_TCP:  tcpcheck.NewChecker(dialer, cfg.HTTP.Timeout),
+		config.SERVICE_TYPE_DNS:  dnscheck.NewChecker(cfg.HTTP.Timeout),
+	})
+}
+
+// CreateTLSProber рукопожатия для tls_certificate и tls_policy через те же резолверы, что и проверки.
+func CreateTLSProber(cfg config.AppConfig) domain.TLSProber {
+	return tlscheck.NewProber(newDialer(cfg.HTTP.DNSResolvers), cfg.HTTP.Timeout)
+}
+
+// CreateDNSResolver запросы к отдельным резолверам для dns_consistency.
+func CreateDNSResolver(cfg config.AppConfig) domain.DNSResolver {
+	return dnscheck.NewResolver(cfg.HTTP.Timeout)
+}
+
+func newHTTPClient(cfg config.HTTP, dialer *net.Dialer) *http.Client {
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+	}
+
+	if len(cfg.DNSResolvers) > 0 {
+		client.Transport = &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		}
+	}
+
+	return client
+}
+
+// skipTLSVerify запрос не прерывается на недоверенном сертификате, его разбирает проверка tls_certificate.
+func skipTLSVerify(client *http.Client) {
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		client.Transport = transport
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+}
+
+// newDialer без dnsAddrs использует системный резолвер.
+func newDialer(dnsAddrs []string) *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+
+	if len(dnsAddrs) == 0 {
+		return dialer
+	}
+
+	dialer.Resolver = &net.Resolver{
+		PreferGo: true, // важно: использовать Go-resolver, чтобы сработал кастомный Dial
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: 3 * time.Second}
+			var lastErr error
+			for _, dnsAddr := range dnsAddrs {
+				conn, err := d.DialContext(ctx, "udp", dnsAddr)
+				if err == nil {
+					return conn, nil
+				}
+				lastErr = err
+			}
+
+			return nil, lastErr
+		},
+	}
+
+	return dialer
+}
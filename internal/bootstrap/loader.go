@@ -2,13 +2,18 @@ package bootstrap
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 
 	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/delivery"
 	"github.com/kias-hack/web-watcher/internal/domain"
 	"github.com/kias-hack/web-watcher/internal/infra/notification"
+	"github.com/kias-hack/web-watcher/internal/infra/storage"
 )
 
-func MapConfigServiceToDomainService(from []*config.Service) []*domain.Service {
+// MapConfigServiceToDomainService prober нужен только сервисам с проверками tls_certificate и tls_policy, dnsResolver — с dns_consistency.
+func MapConfigServiceToDomainService(from []*config.Service, prober domain.TLSProber, dnsResolver domain.DNSResolver) []*domain.Service {
 	var result []*domain.Service
 
 	for _, cfgService := range from {
@@ -16,13 +21,54 @@ func MapConfigServiceToDomainService(from []*config.Service) []*domain.Service {
 		for _, cfgCheck := range cfgService.Check {
 			switch cfgCheck.Type {
 			case config.TYPE_STATUS_CODE:
-				rules = append(rules, domain.NewStatusCodeRule(cfgCheck.Expected))
+				rules = append(rules, domain.NewStatusCodeRule(cfgCheck.Expected, mapClassSeverities(cfgCheck.Severities)))
 			case config.TYPE_BODY_CONTAINS:
-				rules = append(rules, domain.NewBodyMatchRule(cfgCheck.Substring))
+				rules = append(rules, domain.NewBodyMatchRule(cfgCheck.Substrings, cfgCheck.Match == config.MATCH_ANY))
+			case config.TYPE_BODY_NOT_CONTAINS:
+				rules = append(rules, domain.NewBodyNotContainsRule(cfgCheck.Substrings))
+			case config.TYPE_BODY_REGEX:
+				rules = append(rules, domain.NewBodyRegexRule(cfgCheck.Pattern))
+			case config.TYPE_BODY_NOT_REGEX:
+				rules = append(rules, domain.NewBodyNotRegexRule(cfgCheck.Pattern))
 			case config.TYPE_HEADER:
-				rules = append(rules, domain.NewHeaderRule(cfgCheck.HeaderName, cfgCheck.HeaderValue))
+				rules = append(rules, domain.NewHeaderRule(cfgCheck.HeaderName, cfgCheck.HeaderValue, cfgCheck.HeaderMode, cfgCheck.Match == config.MATCH_ANY))
 			case config.TYPE_JSON_FIELD:
-				rules = append(rules, domain.NewJSONFieldRule(cfgCheck.JsonPath, cfgCheck.JsonExpected))
+				rules = append(rules, domain.NewJSONFieldRule(cfgCheck.JsonPath, cfgCheck.JsonOperator, cfgCheck.JsonExpected, cfgCheck.JsonWarnExpected, cfgCheck.JsonContentType))
+			case config.TYPE_JSON_SCHEMA:
+				rules = append(rules, domain.NewJSONSchemaRule(cfgCheck.Schema, *cfgCheck.SchemaMaxErrors))
+			case config.TYPE_HTML_SELECTOR:
+				rules = append(rules, domain.NewHTMLSelectorRule(cfgCheck.Selector, domain.HTMLExpectation{
+					Count:         cfgCheck.Count,
+					CountOperator: cfgCheck.CountOperator,
+					Attribute:     cfgCheck.Attribute,
+					Text:          cfgCheck.Text,
+					Pattern:       cfgCheck.Pattern,
+					Negate:        cfgCheck.Negate,
+				}))
+			case config.TYPE_SECURITY_HEADERS:
+				rules = append(rules, domain.NewSecurityHeadersRule(domain.SecurityHeadersPolicy{
+					HSTSMinMaxAge:         cfgCheck.HSTSMinMaxAge,
+					HSTSIncludeSubDomains: cfgCheck.HSTSIncludeSubDomains,
+					HSTSPreload:           cfgCheck.HSTSPreload,
+					Severities:            mapItemSeverities(config.SecurityHeaderDefaults(), cfgCheck.Severities),
+				}))
+			case config.TYPE_TLS_CERTIFICATE:
+				rules = append(rules, domain.NewTLSCertificateRule(prober, tlsProbeTarget(cfgService), domain.TLSCertificatePolicy{
+					Roots:      cfgCheck.CAPool,
+					Severities: mapItemSeverities(config.TLSCertificateFindings, cfgCheck.Severities),
+				}))
+			case config.TYPE_TLS_POLICY:
+				minVersion, _ := config.ParseTLSVersion(cfgCheck.MinTLSVersion)
+				rules = append(rules, domain.NewTLSPolicyRule(prober, tlsProbeTarget(cfgService), minVersion, cfgCheck.ProbeInterval, mapItemSeverities(config.TLSPolicyFindings, cfgCheck.Severities)))
+			case config.TYPE_DNS_VALUES:
+				rules = append(rules, domain.NewDNSValuesRule(cfgCheck.Values, cfgCheck.Exact))
+			case config.TYPE_DNS_COUNT:
+				rules = append(rules, domain.NewDNSCountRule(cfgCheck.MinCount))
+			case config.TYPE_DNS_TTL:
+				severity, _ := parseSeverity(cfgCheck.Severity)
+				rules = append(rules, domain.NewDNSTTLRule(cfgCheck.MinTTL, cfgCheck.MaxTTL, severity))
+			case config.TYPE_DNS_CONSISTENCY:
+				rules = append(rules, domain.NewDNSConsistencyRule(dnsResolver, serviceHost(cfgService), cfgCheck.RecordType, cfgCheck.Resolvers))
 			case config.TYPE_MAX_LATENCY:
 				rules = append(rules, domain.NewLatencyRule(cfgCheck.MaxLatencyMs))
 			case config.TYPE_SSL_NOT_EXPIRED:
@@ -30,11 +76,43 @@ func MapConfigServiceToDomainService(from []*config.Service) []*domain.Service {
 			}
 		}
 
+		// ответ tcp-сервиса сверяется с expect раньше проверок тела
+		if cfgService.Type == config.SERVICE_TYPE_TCP && cfgService.Expect != "" {
+			rules = append([]domain.CheckRule{domain.NewTCPResponseRule(cfgService.Expect)}, rules...)
+		}
+
 		service := &domain.Service{
 			Name:     cfgService.Name,
+			Type:     cfgService.Type,
 			URL:      cfgService.URL,
 			Interval: cfgService.Interval,
 			Rules:    rules,
+			Address:  cfgService.Address,
+			TLS: domain.TLSEndpoint{
+				StartTLS:   cfgService.StartTLS,
+				ServerName: cfgService.ServerName,
+				SkipVerify: cfgService.TLSSkipVerify,
+			},
+			TCP: domain.TCPExchange{
+				Send:   cfgService.Send,
+				Expect: cfgService.Expect,
+			},
+			DNS: domain.DNSQuery{
+				Host:       cfgService.Host,
+				RecordType: cfgService.RecordType,
+				Resolvers:  cfgService.Resolvers,
+			},
+			Confirm: domain.ConfirmPolicy{
+				Failures:      cfgService.ConfirmFailures,
+				Recoveries:    cfgService.ConfirmRecoveries,
+				RetryInterval: cfgService.RetryInterval,
+			},
+			Flap: domain.FlapPolicy{
+				Enabled:       *cfgService.FlapDetection,
+				Window:        cfgService.FlapWindow,
+				LowThreshold:  *cfgService.FlapLowThreshold,
+				HighThreshold: *cfgService.FlapHighThreshold,
+			},
 		}
 
 		result = append(result, service)
@@ -43,7 +121,8 @@ func MapConfigServiceToDomainService(from []*config.Service) []*domain.Service {
 	return result
 }
 
-func MapConfigNotifierToDomainRoutedNotifier(cfg config.AppConfig) ([]domain.RoutedNotifier, error) {
+// MapConfigNotifierToDomainRoutedNotifier создаёт получателей уведомлений, metrics может быть nil.
+func MapConfigNotifierToDomainRoutedNotifier(cfg config.AppConfig, metrics delivery.Metrics) ([]domain.RoutedNotifier, error) {
 	var result []domain.RoutedNotifier
 
 	for _, cfgNotification := range cfg.Notification {
@@ -58,6 +137,7 @@ func MapConfigNotifierToDomainRoutedNotifier(cfg config.AppConfig) ([]domain.Rou
 		}
 
 		result = append(result, domain.RoutedNotifier{
+			Name: cfgNotification.Name,
 			Rule: domain.AlertRule{
 				ServiceNames:       cfgNotification.ServiceNames,
 				MinSeverity:        severity,
@@ -65,25 +145,155 @@ func MapConfigNotifierToDomainRoutedNotifier(cfg config.AppConfig) ([]domain.Rou
 				RepeatInterval:     cfgNotification.RepeatInterval,
 				NotifyOnRecovery:   *cfgNotification.NotifyOnRecovery,
 			},
-			Notifier: notifier,
+			Notifier: delivery.WithMetrics(cfgNotification.Name, delivery.WithTimeout(notifier, cfgNotification.Timeout), metrics),
 		})
 	}
 
 	return result, nil
 }
 
+func CreateDispatcher(cfg config.AppConfig, notifiers []domain.RoutedNotifier, metrics delivery.Metrics) (*delivery.Dispatcher, error) {
+	var outbox domain.Outbox
+	if cfg.Delivery.OutboxDir != "" {
+		var err error
+		outbox, err = storage.NewFileOutbox(cfg.Delivery.OutboxDir, cfg.Delivery.DeadLetterFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed create outbox: %w", err)
+		}
+	}
+
+	queue := delivery.NewQueue(notifiers, outbox, delivery.RetryPolicy{
+		MaxAttempts:    cfg.Delivery.MaxAttempts,
+		InitialBackoff: cfg.Delivery.InitialBackoff,
+		MaxBackoff:     cfg.Delivery.MaxBackoff,
+		MaxReplays:     cfg.Delivery.MaxReplays,
+	})
+
+	var lanes []delivery.LaneConfig
+	for _, cfgNotification := range cfg.Notification {
+		lanes = append(lanes, delivery.LaneConfig{
+			Name:      cfgNotification.Name,
+			QueueSize: cfgNotification.QueueSize,
+			Workers:   cfgNotification.Workers,
+		})
+	}
+
+	return delivery.NewDispatcher(queue, lanes, metrics), nil
+}
+
+// CreateStateStore возвращает nil, если state_file не задан.
+func CreateStateStore(cfg config.AppConfig) (domain.StateStore, error) {
+	if cfg.Storage.StateFile == "" {
+		return nil, nil
+	}
+
+	store, err := storage.NewFileStateStore(cfg.Storage.StateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed create state store: %w", err)
+	}
+
+	return store, nil
+}
+
+// CreateResultStore возвращает nil, если history_dir не задан.
+func CreateResultStore(cfg config.AppConfig) (domain.ResultStore, error) {
+	if cfg.Storage.HistoryDir == "" {
+		return nil, nil
+	}
+
+	store, err := storage.NewFileResultStore(cfg.Storage.HistoryDir, *cfg.Storage.HistoryRetention)
+	if err != nil {
+		return nil, fmt.Errorf("failed create result store: %w", err)
+	}
+
+	return store, nil
+}
+
 func createNotifierfromConfig(notifierCfg config.Notification, cfg config.AppConfig) (domain.Notifier, error) {
 	if notifierCfg.Type == config.NOTIFIER_TYPE_WEBHOOK {
-		return notification.NewWebHookNotifier(notifierCfg.URL), nil
+		return notification.NewWebHookNotifier(notifierCfg), nil
 	}
 
 	if notifierCfg.Type == config.NOTIFIER_TYPE_EMAIL {
 		return notification.NewEmailNotifier(cfg.SMTP, notifierCfg.EmailTo), nil
 	}
 
+	if notifierCfg.Type == config.NOTIFIER_TYPE_TELEGRAM {
+		return notification.NewTelegramNotifier(notifierCfg), nil
+	}
+
 	return nil, fmt.Errorf("unknown notifier type: %s", notifierCfg.Type)
 }
 
+// mapClassSeverities ключи и значения проверены при загрузке конфига.
+func mapClassSeverities(from map[string]string) map[int]domain.Severity {
+	result := make(map[int]domain.Severity, len(from))
+	for class, severity := range from {
+		classDigit, _ := config.ParseStatusClass(class)
+		result[classDigit], _ = parseSeverity(severity)
+	}
+
+	return result
+}
+
+// tlsProbeTarget для http-сервиса — хост и порт из url (по умолчанию 443), схема https проверена при загрузке конфига.
+func tlsProbeTarget(service *config.Service) domain.TLSProbeTarget {
+	if service.Type == config.SERVICE_TYPE_TLS {
+		serverName := service.ServerName
+		if serverName == "" {
+			serverName, _, _ = net.SplitHostPort(service.Address)
+		}
+
+		return domain.TLSProbeTarget{
+			Address:    service.Address,
+			ServerName: serverName,
+			StartTLS:   service.StartTLS,
+		}
+	}
+
+	serviceURL, _ := url.Parse(service.URL)
+	port := serviceURL.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	return domain.TLSProbeTarget{
+		Address:    net.JoinHostPort(serviceURL.Hostname(), port),
+		ServerName: serviceURL.Hostname(),
+	}
+}
+
+// serviceHost имя, которое проверяет сервис: хост из url, address или host для dns.
+func serviceHost(service *config.Service) string {
+	switch service.Type {
+	case config.SERVICE_TYPE_DNS:
+		return service.Host
+	case config.SERVICE_TYPE_TLS, config.SERVICE_TYPE_TCP:
+		host, _, _ := net.SplitHostPort(service.Address)
+		return host
+	}
+
+	serviceURL, _ := url.Parse(service.URL)
+
+	return serviceURL.Hostname()
+}
+
+// mapItemSeverities уровни пунктов проверки из конфига поверх умолчаний, пункты со значением off не проверяются.
+func mapItemSeverities(defaults map[string]string, from map[string]string) map[string]domain.Severity {
+	result := make(map[string]domain.Severity, len(defaults))
+	for item, severity := range defaults {
+		if configured, ok := from[item]; ok {
+			severity = configured
+		}
+
+		if severity != config.SEVERITY_OFF {
+			result[item], _ = parseSeverity(severity)
+		}
+	}
+
+	return result
+}
+
 func parseSeverity(severity string) (domain.Severity, error) {
 	switch severity {
 	case "ok":
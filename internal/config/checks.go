@@ -1,8 +1,15 @@
 package config
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
 const (
@@ -12,14 +19,76 @@ const (
 	TYPE_JSON_FIELD      = "json_field"
 	TYPE_MAX_LATENCY     = "max_latency"
 	TYPE_HEADER          = "header"
+
+	TYPE_BODY_REGEX        = "body_regex"
+	TYPE_BODY_NOT_CONTAINS = "body_not_contains"
+	TYPE_BODY_NOT_REGEX    = "body_not_regex"
+	TYPE_JSON_SCHEMA       = "json_schema"
+	TYPE_HTML_SELECTOR     = "html_selector"
+	TYPE_SECURITY_HEADERS  = "security_headers"
+	TYPE_TLS_CERTIFICATE   = "tls_certificate"
+	TYPE_TLS_POLICY        = "tls_policy"
+	TYPE_DNS_VALUES        = "dns_values"
+	TYPE_DNS_COUNT         = "dns_count"
+	TYPE_DNS_TTL           = "dns_ttl"
+	TYPE_DNS_CONSISTENCY   = "dns_consistency"
+
+	// результат сверки ответа tcp-сервиса с expect, в секции check не задаётся
+	TYPE_TCP_RESPONSE = "tcp_response"
+)
+
+const (
+	MATCH_ALL = "all"
+	MATCH_ANY = "any"
 )
 
+const (
+	HEADER_MODE_EQUALS   = "equals"
+	HEADER_MODE_CONTAINS = "contains"
+	HEADER_MODE_REGEX    = "regex"
+	HEADER_MODE_PRESENT  = "present"
+	HEADER_MODE_ABSENT   = "absent"
+)
+
+// StringList список строк, в TOML можно указать и одну строку, и массив.
+type StringList []string
+
+func (l *StringList) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case string:
+		*l = StringList{v}
+	case []any:
+		result := make(StringList, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected string, got %T", item)
+			}
+			result = append(result, str)
+		}
+		*l = result
+	default:
+		return fmt.Errorf("expected string or array of strings, got %T", value)
+	}
+
+	return nil
+}
+
 type CheckConfig struct {
-	Type string `toml:"type"` // "status_code", "body_contains", "ssl_not_expired", "json_field", "max_latency", "header"
+	Type string `toml:"type"` // "status_code", "body_contains", "body_not_contains", "body_regex", "body_not_regex", "ssl_not_expired", "json_field", "json_schema", "html_selector", "max_latency", "header", "security_headers", "tls_certificate", "tls_policy"
+
+	// status_code
+	Expected StatusCodes `toml:"expected"`
+	// status_code: уровень для неожиданного кода по классу, например {"4xx" = "warn"}; по умолчанию crit
+	// security_headers: уровень по пункту, например {"csp" = "crit", "version_leak" = "off"}; по умолчанию warn
+	// tls_certificate, tls_policy: уровень по пункту, умолчания в TLSCertificateFindings и TLSPolicyFindings
+	Severities map[string]string `toml:"severities"`
 
-	Expected int `toml:"expected"` // status_code
+	// body_contains, body_not_contains
+	Substrings StringList `toml:"substrings"`
+	Match      string     `toml:"match"` // body_contains, header: "all" (по умолчанию) или "any"
 
-	Substring string `toml:"substrings"` // body_contains
+	Pattern string `toml:"pattern"` // body_regex, body_not_regex, html_selector
 
 	// ssl_not_expired
 	WarnDays int `toml:"warn_days"`
@@ -27,14 +96,79 @@ type CheckConfig struct {
 
 	// json_field
 	JsonPath     string `toml:"json_path"`
-	JsonExpected any    `toml:"json_expected"`
+	JsonOperator string `toml:"operator"` // eq (по умолчанию), ne, lt, lte, gt, gte, in, regex, exists, not_exists, len_eq, len_gt
+	// при невыполнении условия с json_expected — CRIT, с json_warn_expected — WARN
+	JsonExpected     any `toml:"json_expected"`
+	JsonWarnExpected any `toml:"json_warn_expected"`
+	// пусто — application/json или любой тип с суффиксом +json, "any" — не проверять, иначе нужен именно этот тип
+	JsonContentType string `toml:"json_content_type"`
+
+	// json_schema
+	SchemaFile      string             `toml:"schema_file"`       // путь к схеме, относительный — от каталога конфига
+	SchemaMaxErrors *int               `toml:"schema_max_errors"` // сколько нарушений выводить в сообщении, по умолчанию 5
+	Schema          *jsonschema.Schema `toml:"-"`                 // схема, скомпилированная при загрузке конфига
+
+	// html_selector, без count, text и pattern проверяется наличие хотя бы одного элемента
+	Selector      string `toml:"selector"`       // CSS-селектор
+	Count         *int   `toml:"count"`          // ожидаемое число элементов
+	CountOperator string `toml:"count_operator"` // eq (по умолчанию), ne, lt, lte, gt, gte
+	Attribute     string `toml:"attribute"`      // text и pattern проверяют атрибут вместо текста элемента
+	Text          string `toml:"text"`           // точное значение у каждого найденного элемента
+	Negate        bool   `toml:"negate"`         // text или pattern не должны совпасть ни у одного элемента
+
+	// security_headers
+	HSTSMinMaxAge         int  `toml:"hsts_min_max_age"` // секунды, по умолчанию полгода
+	HSTSIncludeSubDomains bool `toml:"hsts_include_subdomains"`
+	HSTSPreload           bool `toml:"hsts_preload"`
+
+	// tls_certificate
+	CAFile string         `toml:"ca_file"` // доверенные корни в PEM вместо системных, относительный путь — от каталога конфига
+	CAPool *x509.CertPool `toml:"-"`       // загружается вместе с конфигом
+
+	// tls_policy: версии ниже считаются небезопасными, по умолчанию 1.2; пробы повторяются не чаще probe_interval
+	MinTLSVersion string        `toml:"min_tls_version"`
+	ProbeInterval time.Duration `toml:"probe_interval"`
+
+	// dns_values: значения, которые должны быть в ответе; с exact других значений быть не должно
+	Values StringList `toml:"values"`
+	Exact  bool       `toml:"exact"`
+
+	// dns_count: сколько записей должно быть в ответе как минимум
+	MinCount int `toml:"min_count"`
+
+	// dns_ttl: границы TTL, нулевая граница не проверяется; уровень при выходе за границы — warn (по умолчанию) или crit
+	MinTTL   time.Duration `toml:"min_ttl"`
+	MaxTTL   time.Duration `toml:"max_ttl"`
+	Severity string        `toml:"severity"`
+
+	// dns_consistency: резолверы, ответы которых сравниваются (по умолчанию резолверы сервиса dns или из [http]),
+	// и тип записи (по умолчанию тип сервиса dns или A)
+	Resolvers  []string `toml:"resolvers"`
+	RecordType string   `toml:"record_type"`
 
 	// max_latency
 	MaxLatencyMs int `toml:"max_latency_ms"`
 
-	// header
+	// header, имя без учёта регистра
 	HeaderName  string `toml:"header_name"`
-	HeaderValue string `toml:"header_value"`
+	HeaderValue string `toml:"header_value"` // для regex — выражение, для present и absent не задаётся
+	HeaderMode  string `toml:"mode"`         // equals (по умолчанию), contains, regex, present, absent
+}
+
+// prepareCheckConfig заполняет значения по умолчанию до валидации.
+func prepareCheckConfig(checks []CheckConfig) {
+	for idx := range checks {
+		switch checks[idx].Type {
+		case TYPE_SECURITY_HEADERS:
+			prepareSecurityHeaders(&checks[idx])
+		case TYPE_TLS_POLICY:
+			prepareTLSPolicy(&checks[idx])
+		case TYPE_DNS_TTL:
+			prepareDNSTTL(&checks[idx])
+		case TYPE_DNS_CONSISTENCY:
+			checks[idx].RecordType = strings.ToUpper(checks[idx].RecordType)
+		}
+	}
 }
 
 func validateCheckConfig(checks []CheckConfig) error {
@@ -43,19 +177,38 @@ func validateCheckConfig(checks []CheckConfig) error {
 	for _, check := range checks {
 		switch check.Type {
 		case TYPE_STATUS_CODE:
-			if check.Expected <= 0 {
+			errs = append(errs, validateStatusCodes(check.Expected, check.Severities)...)
+		case TYPE_BODY_CONTAINS, TYPE_BODY_NOT_CONTAINS:
+			if len(check.Substrings) == 0 || slices.Contains(check.Substrings, "") {
 				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_STATUS_CODE,
-					field:     "expected",
-					msg:       "must be greater than 0",
+					checkType: check.Type,
+					field:     "substrings",
+					msg:       "должны быть непустые строки",
+				})
+			}
+
+			if check.Match != "" && check.Match != MATCH_ALL && check.Match != MATCH_ANY {
+				errs = append(errs, ErrCheckConfigValidation{
+					checkType: check.Type,
+					field:     "match",
+					msg:       "must be one of: all, any",
 				})
 			}
-		case TYPE_BODY_CONTAINS:
-			if check.Substring == "" {
+		case TYPE_BODY_REGEX, TYPE_BODY_NOT_REGEX:
+			if check.Pattern == "" {
+				errs = append(errs, ErrCheckConfigValidation{
+					checkType: check.Type,
+					field:     "pattern",
+					msg:       "must be non-empty string",
+				})
+				continue
+			}
+
+			if _, err := regexp.Compile(check.Pattern); err != nil {
 				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_BODY_CONTAINS,
-					field:     "substring",
-					msg:       "должна быть непустая строка",
+					checkType: check.Type,
+					field:     "pattern",
+					msg:       fmt.Sprintf("invalid regexp: %s", err),
 				})
 			}
 		case TYPE_SSL_NOT_EXPIRED:
@@ -83,37 +236,27 @@ func validateCheckConfig(checks []CheckConfig) error {
 				})
 			}
 		case TYPE_JSON_FIELD:
-			if check.JsonPath == "" {
-				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_JSON_FIELD,
-					field:     "json_path",
-					msg:       "must be non-empty string",
-				})
-			}
-
-			if check.JsonExpected == nil {
-				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_JSON_FIELD,
-					field:     "json_expected",
-					msg:       "must be non-empty",
-				})
-			}
+			errs = append(errs, validateJSONField(check)...)
+		case TYPE_JSON_SCHEMA:
+			errs = append(errs, validateJSONSchema(check)...)
+		case TYPE_HTML_SELECTOR:
+			errs = append(errs, validateHTMLSelector(check)...)
 		case TYPE_HEADER:
-			if check.HeaderName == "" {
-				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_HEADER,
-					field:     "header_name",
-					msg:       "must be non-empty string",
-				})
-			}
-
-			if check.HeaderValue == "" {
-				errs = append(errs, ErrCheckConfigValidation{
-					checkType: TYPE_HEADER,
-					field:     "header_value",
-					msg:       "must be non-empty",
-				})
-			}
+			errs = append(errs, validateHeader(check)...)
+		case TYPE_SECURITY_HEADERS:
+			errs = append(errs, validateSecurityHeaders(check)...)
+		case TYPE_TLS_CERTIFICATE:
+			errs = append(errs, validateTLSCertificate(check)...)
+		case TYPE_TLS_POLICY:
+			errs = append(errs, validateTLSPolicy(check)...)
+		case TYPE_DNS_VALUES:
+			errs = append(errs, validateDNSValues(check)...)
+		case TYPE_DNS_COUNT:
+			errs = append(errs, validateDNSCount(check)...)
+		case TYPE_DNS_TTL:
+			errs = append(errs, validateDNSTTL(check)...)
+		case TYPE_DNS_CONSISTENCY:
+			errs = append(errs, validateDNSConsistency(check)...)
 		case TYPE_MAX_LATENCY:
 			if check.MaxLatencyMs <= 0 {
 				errs = append(errs, ErrCheckConfigValidation{
@@ -129,3 +272,69 @@ func validateCheckConfig(checks []CheckConfig) error {
 
 	return errors.Join(errs...)
 }
+
+func validateHeader(check CheckConfig) []error {
+	var errs []error
+
+	fieldErr := func(field string, msg string) {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_HEADER,
+			field:     field,
+			msg:       msg,
+		})
+	}
+
+	if check.HeaderName == "" {
+		fieldErr("header_name", "must be non-empty string")
+	}
+
+	switch check.HeaderMode {
+	case "", HEADER_MODE_EQUALS, HEADER_MODE_CONTAINS:
+		if check.HeaderValue == "" {
+			fieldErr("header_value", "must be non-empty")
+		}
+	case HEADER_MODE_REGEX:
+		if check.HeaderValue == "" {
+			fieldErr("header_value", "must be non-empty")
+		} else if _, err := regexp.Compile(check.HeaderValue); err != nil {
+			fieldErr("header_value", fmt.Sprintf("invalid regexp: %s", err))
+		}
+	case HEADER_MODE_PRESENT, HEADER_MODE_ABSENT:
+		if check.HeaderValue != "" {
+			fieldErr("header_value", fmt.Sprintf("is not used with mode %s", check.HeaderMode))
+		}
+	default:
+		fieldErr("mode", "must be one of: equals, contains, regex, present, absent")
+	}
+
+	if check.Match != "" && check.Match != MATCH_ALL && check.Match != MATCH_ANY {
+		fieldErr("match", "must be one of: all, any")
+	}
+
+	return errs
+}
+
+// validateItemSeverities ключи severities должны быть из items, значения — warn, crit или off.
+func validateItemSeverities(checkType string, severities map[string]string, items map[string]string) []error {
+	var errs []error
+
+	for item, severity := range severities {
+		if _, ok := items[item]; !ok {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: checkType,
+				field:     "severities",
+				msg:       fmt.Sprintf("unknown item %s", item),
+			})
+		}
+
+		if severity != SEVERITY_WARN && severity != SEVERITY_CRIT && severity != SEVERITY_OFF {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: checkType,
+				field:     "severities",
+				msg:       fmt.Sprintf("value for %s must be one of: warn, crit, off", item),
+			})
+		}
+	}
+
+	return errs
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,31 +17,122 @@ func TestValidateCheckConfig_Cases(t *testing.T) {
 			"status_code - success",
 			CheckConfig{
 				Type:     TYPE_STATUS_CODE,
-				Expected: 200,
+				Expected: StatusCodes{{From: 200, To: 200}},
 			},
 			false,
 		},
+		{
+			"status_code - success class with severities",
+			CheckConfig{
+				Type:       TYPE_STATUS_CODE,
+				Expected:   StatusCodes{{From: 200, To: 299}},
+				Severities: map[string]string{"4xx": SEVERITY_WARN},
+			},
+			false,
+		},
+		{
+			"status_code - invalid range",
+			CheckConfig{
+				Type:     TYPE_STATUS_CODE,
+				Expected: StatusCodes{{From: 299, To: 200}},
+			},
+			true,
+		},
+		{
+			"status_code - invalid severity class",
+			CheckConfig{
+				Type:       TYPE_STATUS_CODE,
+				Expected:   StatusCodes{{From: 200, To: 200}},
+				Severities: map[string]string{"45x": SEVERITY_WARN},
+			},
+			true,
+		},
+		{
+			"status_code - invalid severity value",
+			CheckConfig{
+				Type:       TYPE_STATUS_CODE,
+				Expected:   StatusCodes{{From: 200, To: 200}},
+				Severities: map[string]string{"4xx": "ok"},
+			},
+			true,
+		},
 		{
 			"status_code - invalid",
 			CheckConfig{
 				Type:     TYPE_STATUS_CODE,
-				Expected: 0,
+				Expected: StatusCodes{{From: 0, To: 0}},
 			},
 			true,
 		},
 		{
 			"body_contains - success",
 			CheckConfig{
-				Type:      TYPE_BODY_CONTAINS,
-				Substring: "ok",
+				Type:       TYPE_BODY_CONTAINS,
+				Substrings: StringList{"ok"},
+			},
+			false,
+		},
+		{
+			"body_contains - success any",
+			CheckConfig{
+				Type:       TYPE_BODY_CONTAINS,
+				Substrings: StringList{"ok", "готово"},
+				Match:      MATCH_ANY,
 			},
 			false,
 		},
+		{
+			"body_contains - invalid match",
+			CheckConfig{
+				Type:       TYPE_BODY_CONTAINS,
+				Substrings: StringList{"ok"},
+				Match:      "some",
+			},
+			true,
+		},
+		{
+			"body_contains - invalid empty item",
+			CheckConfig{
+				Type:       TYPE_BODY_CONTAINS,
+				Substrings: StringList{"ok", ""},
+			},
+			true,
+		},
 		{
 			"body_contains - invalid empty",
 			CheckConfig{
-				Type:      TYPE_BODY_CONTAINS,
-				Substring: "",
+				Type:       TYPE_BODY_CONTAINS,
+				Substrings: nil,
+			},
+			true,
+		},
+		{
+			"body_not_contains - invalid empty",
+			CheckConfig{
+				Type: TYPE_BODY_NOT_CONTAINS,
+			},
+			true,
+		},
+		{
+			"body_regex - success",
+			CheckConfig{
+				Type:    TYPE_BODY_REGEX,
+				Pattern: `version: (?P<version>[\d.]+)`,
+			},
+			false,
+		},
+		{
+			"body_regex - invalid empty",
+			CheckConfig{
+				Type: TYPE_BODY_REGEX,
+			},
+			true,
+		},
+		{
+			"body_not_regex - invalid regexp",
+			CheckConfig{
+				Type:    TYPE_BODY_NOT_REGEX,
+				Pattern: "Fatal (error",
 			},
 			true,
 		},
@@ -115,6 +207,97 @@ func TestValidateCheckConfig_Cases(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"json_field - success thresholds",
+			CheckConfig{
+				Type:             TYPE_JSON_FIELD,
+				JsonPath:         "queue_depth",
+				JsonOperator:     JSON_OP_LT,
+				JsonExpected:     int64(1000),
+				JsonWarnExpected: int64(500),
+			},
+			false,
+		},
+		{
+			"json_field - success exists without expected",
+			CheckConfig{
+				Type:         TYPE_JSON_FIELD,
+				JsonPath:     "db",
+				JsonOperator: JSON_OP_EXISTS,
+			},
+			false,
+		},
+		{
+			"json_field - invalid unknown operator",
+			CheckConfig{
+				Type:         TYPE_JSON_FIELD,
+				JsonPath:     "status",
+				JsonOperator: "like",
+				JsonExpected: "ok",
+			},
+			true,
+		},
+		{
+			"json_field - invalid non numeric threshold",
+			CheckConfig{
+				Type:             TYPE_JSON_FIELD,
+				JsonPath:         "queue_depth",
+				JsonOperator:     JSON_OP_GT,
+				JsonExpected:     int64(1),
+				JsonWarnExpected: "many",
+			},
+			true,
+		},
+		{
+			"json_field - invalid in without array",
+			CheckConfig{
+				Type:         TYPE_JSON_FIELD,
+				JsonPath:     "status",
+				JsonOperator: JSON_OP_IN,
+				JsonExpected: "ok",
+			},
+			true,
+		},
+		{
+			"json_field - invalid regexp",
+			CheckConfig{
+				Type:         TYPE_JSON_FIELD,
+				JsonPath:     "version",
+				JsonOperator: JSON_OP_REGEX,
+				JsonExpected: "^(2",
+			},
+			true,
+		},
+		{
+			"json_field - success content type",
+			CheckConfig{
+				Type:            TYPE_JSON_FIELD,
+				JsonPath:        "status",
+				JsonExpected:    "ok",
+				JsonContentType: "application/vnd.api+json",
+			},
+			false,
+		},
+		{
+			"json_field - invalid content type",
+			CheckConfig{
+				Type:            TYPE_JSON_FIELD,
+				JsonPath:        "status",
+				JsonExpected:    "ok",
+				JsonContentType: "application/",
+			},
+			true,
+		},
+		{
+			"json_field - invalid expected for exists",
+			CheckConfig{
+				Type:         TYPE_JSON_FIELD,
+				JsonPath:     "db",
+				JsonOperator: JSON_OP_EXISTS,
+				JsonExpected: true,
+			},
+			true,
+		},
 		{
 			"max_latency - success",
 			CheckConfig{
@@ -131,6 +314,73 @@ func TestValidateCheckConfig_Cases(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"html_selector - success presence",
+			CheckConfig{
+				Type:     TYPE_HTML_SELECTOR,
+				Selector: ".price",
+			},
+			false,
+		},
+		{
+			"html_selector - success count and negated attribute pattern",
+			CheckConfig{
+				Type:          TYPE_HTML_SELECTOR,
+				Selector:      "meta[name=robots]",
+				Count:         ptr(1),
+				CountOperator: JSON_OP_LTE,
+				Attribute:     "content",
+				Pattern:       "noindex",
+				Negate:        true,
+			},
+			false,
+		},
+		{
+			"html_selector - invalid selector",
+			CheckConfig{
+				Type:     TYPE_HTML_SELECTOR,
+				Selector: "div[",
+			},
+			true,
+		},
+		{
+			"html_selector - count_operator without count",
+			CheckConfig{
+				Type:          TYPE_HTML_SELECTOR,
+				Selector:      "title",
+				CountOperator: JSON_OP_GT,
+			},
+			true,
+		},
+		{
+			"html_selector - invalid count_operator",
+			CheckConfig{
+				Type:          TYPE_HTML_SELECTOR,
+				Selector:      "title",
+				Count:         ptr(1),
+				CountOperator: JSON_OP_IN,
+			},
+			true,
+		},
+		{
+			"html_selector - text and pattern together",
+			CheckConfig{
+				Type:     TYPE_HTML_SELECTOR,
+				Selector: "title",
+				Text:     "Главная",
+				Pattern:  "Глав",
+			},
+			true,
+		},
+		{
+			"html_selector - negate without value check",
+			CheckConfig{
+				Type:     TYPE_HTML_SELECTOR,
+				Selector: "title",
+				Negate:   true,
+			},
+			true,
+		},
 		{
 			"header - success",
 			CheckConfig{
@@ -158,6 +408,175 @@ func TestValidateCheckConfig_Cases(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"header - success absent without value",
+			CheckConfig{
+				Type:       TYPE_HEADER,
+				HeaderName: "X-Powered-By",
+				HeaderMode: HEADER_MODE_ABSENT,
+			},
+			false,
+		},
+		{
+			"header - invalid value with mode present",
+			CheckConfig{
+				Type:        TYPE_HEADER,
+				HeaderName:  "X-Custom",
+				HeaderValue: "value",
+				HeaderMode:  HEADER_MODE_PRESENT,
+			},
+			true,
+		},
+		{
+			"header - invalid regex",
+			CheckConfig{
+				Type:        TYPE_HEADER,
+				HeaderName:  "Cache-Control",
+				HeaderValue: "max-age=(",
+				HeaderMode:  HEADER_MODE_REGEX,
+			},
+			true,
+		},
+		{
+			"header - unknown mode",
+			CheckConfig{
+				Type:        TYPE_HEADER,
+				HeaderName:  "X-Custom",
+				HeaderValue: "value",
+				HeaderMode:  "starts_with",
+			},
+			true,
+		},
+		{
+			"security_headers - success defaults",
+			CheckConfig{
+				Type: TYPE_SECURITY_HEADERS,
+			},
+			false,
+		},
+		{
+			"security_headers - success severities",
+			CheckConfig{
+				Type:       TYPE_SECURITY_HEADERS,
+				Severities: map[string]string{SECURITY_HEADER_CSP: SEVERITY_CRIT, SECURITY_HEADER_VERSION_LEAK: SEVERITY_OFF},
+			},
+			false,
+		},
+		{
+			"security_headers - unknown item",
+			CheckConfig{
+				Type:       TYPE_SECURITY_HEADERS,
+				Severities: map[string]string{"x_xss_protection": SEVERITY_WARN},
+			},
+			true,
+		},
+		{
+			"security_headers - invalid severity",
+			CheckConfig{
+				Type:       TYPE_SECURITY_HEADERS,
+				Severities: map[string]string{SECURITY_HEADER_HSTS: "ok"},
+			},
+			true,
+		},
+		{
+			"security_headers - negative hsts_min_max_age",
+			CheckConfig{
+				Type:          TYPE_SECURITY_HEADERS,
+				HSTSMinMaxAge: -1,
+			},
+			true,
+		},
+		{
+			"tls_certificate - success severities",
+			CheckConfig{
+				Type:       TYPE_TLS_CERTIFICATE,
+				Severities: map[string]string{TLS_FINDING_WEAK_KEY: SEVERITY_CRIT, TLS_FINDING_HOSTNAME: SEVERITY_OFF},
+			},
+			false,
+		},
+		{
+			"tls_certificate - unknown item",
+			CheckConfig{
+				Type:       TYPE_TLS_CERTIFICATE,
+				Severities: map[string]string{"revoked": SEVERITY_CRIT},
+			},
+			true,
+		},
+		{
+			"tls_policy - success",
+			CheckConfig{
+				Type:          TYPE_TLS_POLICY,
+				MinTLSVersion: "1.3",
+				Severities:    map[string]string{TLS_POLICY_WEAK_CIPHER: SEVERITY_OFF},
+			},
+			false,
+		},
+		{
+			"tls_policy - unknown min_tls_version",
+			CheckConfig{
+				Type:          TYPE_TLS_POLICY,
+				MinTLSVersion: "1.4",
+			},
+			true,
+		},
+		{
+			"tls_policy - negative probe_interval",
+			CheckConfig{
+				Type:          TYPE_TLS_POLICY,
+				MinTLSVersion: "1.2",
+				ProbeInterval: -time.Minute,
+			},
+			true,
+		},
+		{
+			"tls_policy - unknown item",
+			CheckConfig{
+				Type:          TYPE_TLS_POLICY,
+				MinTLSVersion: "1.2",
+				Severities:    map[string]string{"sslv3": SEVERITY_CRIT},
+			},
+			true,
+		},
+		{
+			"dns_values - empty values",
+			CheckConfig{Type: TYPE_DNS_VALUES},
+			true,
+		},
+		{
+			"dns_count - zero min_count",
+			CheckConfig{Type: TYPE_DNS_COUNT},
+			true,
+		},
+		{
+			"dns_ttl - success",
+			CheckConfig{Type: TYPE_DNS_TTL, MinTTL: time.Minute, MaxTTL: time.Hour, Severity: SEVERITY_CRIT},
+			false,
+		},
+		{
+			"dns_ttl - without bounds",
+			CheckConfig{Type: TYPE_DNS_TTL, Severity: SEVERITY_WARN},
+			true,
+		},
+		{
+			"dns_ttl - min greater than max",
+			CheckConfig{Type: TYPE_DNS_TTL, MinTTL: time.Hour, MaxTTL: time.Minute, Severity: SEVERITY_WARN},
+			true,
+		},
+		{
+			"dns_ttl - unknown severity",
+			CheckConfig{Type: TYPE_DNS_TTL, MinTTL: time.Minute, Severity: SEVERITY_OFF},
+			true,
+		},
+		{
+			"dns_consistency - unknown record_type",
+			CheckConfig{Type: TYPE_DNS_CONSISTENCY, RecordType: "SRV", Resolvers: []string{"127.0.0.1:53", "127.0.0.2:53"}},
+			true,
+		},
+		{
+			"dns_consistency - invalid resolver",
+			CheckConfig{Type: TYPE_DNS_CONSISTENCY, Resolvers: []string{"127.0.0.1", "127.0.0.2:53"}},
+			true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -1,15 +1,19 @@
 package config
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 )
 
 func CreateConfig(configPath string) (*AppConfig, error) {
@@ -32,23 +36,52 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 		return nil, errors.New("services not found")
 	}
 
-	templatesMap := make(map[string][]CheckConfig)
+	templatesMap := make(map[string]Template)
 	for _, template := range config.Templates {
-		templatesMap[template.Name] = template.Checks
+		templatesMap[template.Name] = template
 	}
 
+	schemas := make(map[string]*jsonschema.Schema)
+	caBundles := make(map[string]*x509.CertPool)
 	serviceNames := make(map[string]struct{})
 	for idx, service := range config.Services {
 		slog.Debug("service", "o", service)
 		for _, tplName := range service.UseTemplates {
-			checks, ok := templatesMap[tplName]
+			template, ok := templatesMap[tplName]
 			if !ok {
 				return nil, fmt.Errorf("service [%s] - template `%s` not found", service.Name, tplName)
 			}
 
-			service.Check = append(service.Check, checks...)
+			service.Check = append(service.Check, template.Checks...)
+
+			// настройки сервиса приоритетнее, из шаблонов берётся первое заданное значение
+			if service.ConfirmFailures == 0 {
+				service.ConfirmFailures = template.ConfirmFailures
+			}
+			if service.ConfirmRecoveries == 0 {
+				service.ConfirmRecoveries = template.ConfirmRecoveries
+			}
+			if service.RetryInterval == 0 {
+				service.RetryInterval = template.RetryInterval
+			}
+			if service.FlapDetection == nil {
+				service.FlapDetection = template.FlapDetection
+			}
+			if service.FlapWindow == 0 {
+				service.FlapWindow = template.FlapWindow
+			}
+			if service.FlapLowThreshold == nil {
+				service.FlapLowThreshold = template.FlapLowThreshold
+			}
+			if service.FlapHighThreshold == nil {
+				service.FlapHighThreshold = template.FlapHighThreshold
+			}
 		}
 
+		prepareServiceType(service)
+		prepareServiceConfirm(service)
+		prepareServiceFlap(service)
+
 		if err := validateService(service); err != nil {
 			return nil, fmt.Errorf("found error in service[%d]: %w", idx, err)
 		}
@@ -57,10 +90,24 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 			return nil, fmt.Errorf("service [%d] - checks can`t be empty", idx)
 		}
 
+		if err := validateServiceChecks(service); err != nil {
+			return nil, fmt.Errorf("found error in service(%s).check: %w", service.Name, err)
+		}
+
+		prepareCheckConfig(service.Check)
+
 		if err := validateCheckConfig(service.Check); err != nil {
 			return nil, fmt.Errorf("found error in service(%s).check: %w", service.Name, err)
 		}
 
+		if err := prepareJSONSchemas(service.Check, filepath.Dir(configPath), schemas); err != nil {
+			return nil, fmt.Errorf("found error in service(%s).check: %w", service.Name, err)
+		}
+
+		if err := prepareCABundles(service.Check, filepath.Dir(configPath), caBundles); err != nil {
+			return nil, fmt.Errorf("found error in service(%s).check: %w", service.Name, err)
+		}
+
 		if _, ok := serviceNames[service.Name]; ok {
 			return nil, fmt.Errorf("service name duplicate: %s", service.Name)
 		}
@@ -73,7 +120,25 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 	}
 
 	var haveEmailNotifier bool
+	notifierNames := make(map[string]struct{})
+	defaultNames := make(map[string]int)
 	for idx, notification := range config.Notification {
+		if notification.Name == "" {
+			// блоки с одним адресатом различаются суффиксом по порядку среди таких блоков
+			name := defaultNotificationName(notification)
+			defaultNames[name]++
+			if defaultNames[name] > 1 {
+				name = fmt.Sprintf("%s-%d", name, defaultNames[name])
+			}
+
+			config.Notification[idx].Name = name
+		}
+
+		if _, ok := notifierNames[config.Notification[idx].Name]; ok {
+			return nil, fmt.Errorf("notification name duplicate: %s", config.Notification[idx].Name)
+		}
+		notifierNames[config.Notification[idx].Name] = struct{}{}
+
 		if notification.RepeatInterval.Seconds() == 0 {
 			config.Notification[idx].RepeatInterval = 4 * time.Hour
 		}
@@ -90,6 +155,36 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 		if config.Notification[idx].NotifyOnRecovery == nil {
 			config.Notification[idx].NotifyOnRecovery = ptr(true)
 		}
+
+		if notification.Type == NOTIFIER_TYPE_WEBHOOK && notification.Method == "" {
+			config.Notification[idx].Method = http.MethodPost
+		}
+
+		if notification.Type == NOTIFIER_TYPE_TELEGRAM {
+			if notification.APIURL == "" {
+				config.Notification[idx].APIURL = TELEGRAM_DEFAULT_API_URL
+			}
+
+			if notification.ParseMode == "" {
+				config.Notification[idx].ParseMode = TELEGRAM_PARSE_MODE_HTML
+			}
+		}
+
+		if notification.Timeout == 0 {
+			if notification.Type == NOTIFIER_TYPE_EMAIL {
+				config.Notification[idx].Timeout = 30 * time.Second
+			} else {
+				config.Notification[idx].Timeout = 5 * time.Second
+			}
+		}
+
+		if notification.QueueSize == 0 {
+			config.Notification[idx].QueueSize = 100
+		}
+
+		if notification.Workers == 0 {
+			config.Notification[idx].Workers = 1
+		}
 	}
 
 	if haveEmailNotifier {
@@ -98,6 +193,22 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 		}
 	}
 
+	if err := prepareDelivery(&config.Delivery); err != nil {
+		return nil, fmt.Errorf("invalid delivery settings: %w", err)
+	}
+
+	if err := prepareStorage(&config.Storage); err != nil {
+		return nil, fmt.Errorf("invalid storage settings: %w", err)
+	}
+
+	if err := validateListen(config.Metrics.Listen); err != nil {
+		return nil, fmt.Errorf("invalid metrics settings: %w", err)
+	}
+
+	if err := validateListen(config.Status.Listen); err != nil {
+		return nil, fmt.Errorf("invalid status settings: %w", err)
+	}
+
 	if config.HTTP.Timeout.Seconds() == 0 {
 		config.HTTP.Timeout = 2 * time.Second
 	}
@@ -120,6 +231,10 @@ func CreateConfig(configPath string) (*AppConfig, error) {
 		config.HTTP.DNSResolvers = dnsResolvers
 	}
 
+	if err := prepareDNSResolvers(config); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -128,28 +243,90 @@ func validateService(service *Service) error {
 		return fmt.Errorf("service name can`t be empty")
 	}
 
-	if service.URL == "" {
-		return fmt.Errorf("service url can`t be empty")
+	if err := validateServiceTarget(service); err != nil {
+		return err
 	}
 
 	if service.Interval.Seconds() < 1 {
 		return fmt.Errorf("service interval must be grather than 1s")
 	}
 
+	if service.ConfirmFailures < 1 || service.ConfirmRecoveries < 1 {
+		return fmt.Errorf("confirm_failures and confirm_recoveries must be greater than 0")
+	}
+
+	if service.RetryInterval.Seconds() < 1 {
+		return fmt.Errorf("service retry_interval must be grather than 1s")
+	}
+
+	if service.FlapWindow < 3 {
+		return fmt.Errorf("flap_window must be greater than 2")
+	}
+
+	if *service.FlapLowThreshold < 0 || *service.FlapHighThreshold > 100 || *service.FlapLowThreshold >= *service.FlapHighThreshold {
+		return fmt.Errorf("flap thresholds must satisfy 0 <= flap_low_threshold < flap_high_threshold <= 100")
+	}
+
 	return nil
 }
 
+// prepareServiceFlap значения по умолчанию как у Nagios для сервисов: окно 21 проверка, пороги 5% и 20%.
+func prepareServiceFlap(service *Service) {
+	if service.FlapDetection == nil {
+		service.FlapDetection = ptr(false)
+	}
+
+	if service.FlapWindow == 0 {
+		service.FlapWindow = 21
+	}
+
+	if service.FlapLowThreshold == nil {
+		service.FlapLowThreshold = ptr(5.0)
+	}
+
+	if service.FlapHighThreshold == nil {
+		service.FlapHighThreshold = ptr(20.0)
+	}
+}
+
+func prepareServiceConfirm(service *Service) {
+	if service.ConfirmFailures == 0 {
+		service.ConfirmFailures = 1
+	}
+
+	if service.ConfirmRecoveries == 0 {
+		service.ConfirmRecoveries = 1
+	}
+
+	if service.RetryInterval == 0 {
+		service.RetryInterval = service.Interval
+	}
+}
+
 type AppConfig struct {
 	Services     []*Service     `toml:"services"`
 	Notification []Notification `toml:"notification"`
 	SMTP         SMTPConnection `toml:"smtp"`
 	Templates    []Template     `toml:"templates"`
 	HTTP         HTTP           `toml:"http"`
+	Delivery     Delivery       `toml:"delivery"`
+	Storage      Storage        `toml:"storage"`
+	Metrics      Metrics        `toml:"metrics"`
+	Status       Status         `toml:"status"`
 }
 
 type Template struct {
 	Name   string        `toml:"name"`
 	Checks []CheckConfig `toml:"checks"`
+
+	ConfirmFailures   int           `toml:"confirm_failures"`
+	ConfirmRecoveries int           `toml:"confirm_recoveries"`
+	RetryInterval     time.Duration `toml:"retry_interval"`
+
+	FlapDetection     *bool    `toml:"flap_detection"`
+	FlapWindow        int      `toml:"flap_window"`
+	FlapLowThreshold  *float64 `toml:"flap_low_threshold"`
+	FlapHighThreshold *float64 `toml:"flap_high_threshold"`
 }
 
 type HTTP struct {
@@ -159,11 +336,40 @@ type HTTP struct {
 
 type Service struct {
 	Name     string        `toml:"name"`
+	Type     string        `toml:"type"` // http (по умолчанию), tls, tcp, dns
 	URL      string        `toml:"url"`
 	Interval time.Duration `toml:"interval"`
 
+	// не прерывать проверку на недоверенном сертификате, его разбирает проверка tls_certificate
+	TLSSkipVerify bool `toml:"tls_skip_verify"`
+
+	// tls и tcp: адрес host:port; для tls необязательные STARTTLS и имя для SNI (по умолчанию хост из address)
+	Address    string `toml:"address"`
+	StartTLS   string `toml:"starttls"`
+	ServerName string `toml:"server_name"`
+
+	// tcp: что отправить после подключения и какого ответа ждать (регулярное выражение)
+	Send   string `toml:"send"`
+	Expect string `toml:"expect"`
+
+	// dns: имя и тип запрашиваемой записи (по умолчанию A), резолверы host:port — по умолчанию из [http]
+	Host       string   `toml:"host"`
+	RecordType string   `toml:"record_type"`
+	Resolvers  []string `toml:"resolvers"`
+
 	Check        []CheckConfig `toml:"check"`
 	UseTemplates []string      `toml:"use_templates"`
+
+	// подтверждение смены состояния: сколько проверок подряд должны дать новый результат
+	// и через какое время перепроверять, пока изменение не подтверждено
+	ConfirmFailures   int           `toml:"confirm_failures"`
+	ConfirmRecoveries int           `toml:"confirm_recoveries"`
+	RetryInterval     time.Duration `toml:"retry_interval"`
+
+	FlapDetection     *bool    `toml:"flap_detection"`
+	FlapWindow        int      `toml:"flap_window"`
+	FlapLowThreshold  *float64 `toml:"flap_low_threshold"`
+	FlapHighThreshold *float64 `toml:"flap_high_threshold"`
 }
 
 func ptr[T any](v T) *T { return &v }
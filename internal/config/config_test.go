@@ -1,8 +1,16 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -149,6 +157,230 @@ expected = 200
 		assert.NoError(t, err)
 	})
 
+	t.Run("check webhook defaults", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[notification.headers]
+Authorization = "Bearer token"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "POST", cfg.Notification[0].Method)
+		assert.Equal(t, 5*time.Second, cfg.Notification[0].Timeout)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, cfg.Notification[0].Headers)
+		assert.Equal(t, 100, cfg.Notification[0].QueueSize)
+		assert.Equal(t, 1, cfg.Notification[0].Workers)
+	})
+
+	t.Run("check telegram defaults", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "telegram"
+services = ["example.ru"]
+min_severity = "ok"
+bot_token = "123:token"
+chat_id = "-100500"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, TELEGRAM_DEFAULT_API_URL, cfg.Notification[0].APIURL)
+		assert.Equal(t, TELEGRAM_PARSE_MODE_HTML, cfg.Notification[0].ParseMode)
+		assert.Equal(t, 5*time.Second, cfg.Notification[0].Timeout)
+	})
+
+	t.Run("check delivery defaults and notifier names", func(t *testing.T) {
+		configContent := `
+[delivery]
+outbox_dir = "/var/lib/web-watcher/outbox"
+
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[notification]]
+name = "oncall"
+type = "webhook"
+services = ["example.ru"]
+min_severity = "crit"
+url = "https://example.com/oncall"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultNotificationName(Notification{Type: NOTIFIER_TYPE_WEBHOOK, URL: "https://example.com/"}), cfg.Notification[0].Name)
+		assert.Regexp(t, `^webhook-[0-9a-f]{8}$`, cfg.Notification[0].Name)
+		assert.Equal(t, "oncall", cfg.Notification[1].Name)
+		assert.Equal(t, 3, cfg.Delivery.MaxAttempts)
+		assert.Equal(t, time.Second, cfg.Delivery.InitialBackoff)
+		assert.Equal(t, 30*time.Second, cfg.Delivery.MaxBackoff)
+		assert.Equal(t, 5, cfg.Delivery.MaxReplays)
+		assert.Equal(t, "/var/lib/web-watcher/outbox/dead-letter.jsonl", cfg.Delivery.DeadLetterFile)
+		assert.Equal(t, DEFAULT_HISTORY_RETENTION, *cfg.Storage.HistoryRetention)
+	})
+
+	t.Run("zero history_retention keeps everything", func(t *testing.T) {
+		path := createConfig(t, `
+[storage]
+history_dir = "/var/lib/web-watcher/history"
+history_retention = "0s"
+
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), *cfg.Storage.HistoryRetention)
+	})
+
+	t.Run("notification name duplicate", func(t *testing.T) {
+		configContent := `
+[[notification]]
+name = "oncall"
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[notification]]
+name = "oncall"
+type = "webhook"
+services = ["example.ru"]
+min_severity = "crit"
+url = "https://example.com/oncall"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "notification name duplicate: oncall")
+	})
+
+	t.Run("unnamed notifications with the same target", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "crit"
+url = "https://example.com/"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+
+		name := defaultNotificationName(cfg.Notification[0])
+		assert.Equal(t, name, cfg.Notification[0].Name)
+		assert.Equal(t, name+"-2", cfg.Notification[1].Name)
+	})
+
+	t.Run("invalid delivery backoff", func(t *testing.T) {
+		configContent := `
+[delivery]
+initial_backoff = "1m"
+max_backoff = "10s"
+
+[[notification]]
+type = "webhook"
+services = ["example.ru"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "example.ru"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "invalid delivery settings")
+	})
+
 	t.Run("got check with unknown type", func(t *testing.T) {
 		configContent := `
 [[notification]]
@@ -206,6 +438,89 @@ expected = 200
 		assert.Equal(t, 4*time.Hour, cfg.Notification[0].RepeatInterval)
 	})
 
+	t.Run("status_code expected as set, class and range", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "10s"
+
+[[services.check]]
+type = "status_code"
+expected = [200, "204", "3xx", "401-403"]
+severities = { "4xx" = "warn", "5xx" = "crit" }
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCodes{
+			{From: 200, To: 200},
+			{From: 204, To: 204},
+			{From: 300, To: 399},
+			{From: 401, To: 403},
+		}, cfg.Services[0].Check[0].Expected)
+		assert.Equal(t, map[string]string{"4xx": "warn", "5xx": "crit"}, cfg.Services[0].Check[0].Severities)
+	})
+
+	t.Run("status_code with invalid expected", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "10s"
+
+[[services.check]]
+type = "status_code"
+expected = "2xy"
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, `invalid status code "2xy"`)
+	})
+
+	t.Run("body_contains substrings as list", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "10s"
+
+[[services.check]]
+type = "body_contains"
+substrings = ["<title>Example</title>", "footer"]
+match = "any"
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, StringList{"<title>Example</title>", "footer"}, cfg.Services[0].Check[0].Substrings)
+		assert.Equal(t, MATCH_ANY, cfg.Services[0].Check[0].Match)
+	})
+
 	t.Run("service with templates gets checks from templates", func(t *testing.T) {
 		configContent := `
 [[notification]]
@@ -251,9 +566,9 @@ substrings = "ok"
 		assert.Len(t, checks, 4)
 
 		assert.Equal(t, "body_contains", checks[0].Type)
-		assert.Equal(t, "ok", checks[0].Substring)
+		assert.Equal(t, StringList{"ok"}, checks[0].Substrings)
 		assert.Equal(t, "status_code", checks[1].Type)
-		assert.Equal(t, 200, checks[1].Expected)
+		assert.Equal(t, StatusCodes{{From: 200, To: 200}}, checks[1].Expected)
 		assert.Equal(t, "max_latency", checks[2].Type)
 		assert.Equal(t, 500, checks[2].MaxLatencyMs)
 		assert.Equal(t, "ssl_not_expired", checks[3].Type)
@@ -289,22 +604,35 @@ use_templates = ["http_ok", "missing"]
 		assert.ErrorContains(t, err, "template `missing` not found")
 	})
 
-	t.Run("read optional http dns_resolver list from config", func(t *testing.T) {
+	t.Run("confirm settings come from service, then templates, then defaults", func(t *testing.T) {
 		configContent := `
-[http]
-timeout = "5s"
-dns_resolvers = ["1.1.1.1:53", "8.8.8.8:53"]
-
 [[notification]]
 type = "webhook"
 services = ["svc"]
 min_severity = "ok"
 url = "https://example.com/"
 
+[[templates]]
+name = "flaky"
+confirm_failures = 3
+confirm_recoveries = 2
+retry_interval = "5s"
+
+[[templates.checks]]
+type = "status_code"
+expected = 200
+
 [[services]]
 name = "svc"
 url = "https://example.ru"
-interval = "5s"
+interval = "1m"
+use_templates = ["flaky"]
+confirm_recoveries = 1
+
+[[services]]
+name = "plain"
+url = "https://example.com"
+interval = "30s"
 
 [[services.check]]
 type = "status_code"
@@ -315,13 +643,220 @@ expected = 200
 
 		cfg, err := CreateConfig(path)
 		assert.NoError(t, err)
-		assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, cfg.HTTP.DNSResolvers)
-		assert.Equal(t, 5*time.Second, cfg.HTTP.Timeout)
+
+		assert.Equal(t, 3, cfg.Services[0].ConfirmFailures)
+		assert.Equal(t, 1, cfg.Services[0].ConfirmRecoveries)
+		assert.Equal(t, 5*time.Second, cfg.Services[0].RetryInterval)
+
+		assert.Equal(t, 1, cfg.Services[1].ConfirmFailures)
+		assert.Equal(t, 1, cfg.Services[1].ConfirmRecoveries)
+		assert.Equal(t, 30*time.Second, cfg.Services[1].RetryInterval)
 	})
 
-	t.Run("invalid http dns_resolver address", func(t *testing.T) {
+	t.Run("invalid confirm settings", func(t *testing.T) {
 		configContent := `
-[http]
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "1m"
+confirm_failures = -1
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "confirm_failures and confirm_recoveries must be greater than 0")
+	})
+
+	t.Run("flap settings come from templates, then defaults", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[templates]]
+name = "flappy"
+flap_detection = true
+flap_window = 11
+flap_high_threshold = 40
+
+[[templates.checks]]
+type = "status_code"
+expected = 200
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "1m"
+use_templates = ["flappy"]
+
+[[services]]
+name = "plain"
+url = "https://example.com"
+interval = "30s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+
+		assert.True(t, *cfg.Services[0].FlapDetection)
+		assert.Equal(t, 11, cfg.Services[0].FlapWindow)
+		assert.Equal(t, 5.0, *cfg.Services[0].FlapLowThreshold)
+		assert.Equal(t, 40.0, *cfg.Services[0].FlapHighThreshold)
+
+		assert.False(t, *cfg.Services[1].FlapDetection)
+		assert.Equal(t, 21, cfg.Services[1].FlapWindow)
+		assert.Equal(t, 5.0, *cfg.Services[1].FlapLowThreshold)
+		assert.Equal(t, 20.0, *cfg.Services[1].FlapHighThreshold)
+	})
+
+	t.Run("explicit zero flap_low_threshold is kept", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc", "plain"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[templates]]
+name = "flappy"
+flap_low_threshold = 10
+
+[[templates.checks]]
+type = "status_code"
+expected = 200
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "1m"
+use_templates = ["flappy"]
+flap_low_threshold = 0
+
+[[services]]
+name = "plain"
+url = "https://example.com"
+interval = "30s"
+flap_low_threshold = 0
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, *cfg.Services[0].FlapLowThreshold)
+		assert.Equal(t, 0.0, *cfg.Services[1].FlapLowThreshold)
+	})
+
+	t.Run("invalid flap thresholds", func(t *testing.T) {
+		configContent := `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "1m"
+flap_detection = true
+flap_low_threshold = 30
+flap_high_threshold = 20
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "flap thresholds must satisfy")
+	})
+
+	t.Run("invalid metrics listen address", func(t *testing.T) {
+		configContent := `
+[metrics]
+listen = "9090"
+
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "1m"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "invalid metrics settings")
+	})
+
+	t.Run("read optional http dns_resolver list from config", func(t *testing.T) {
+		configContent := `
+[http]
+timeout = "5s"
+dns_resolvers = ["1.1.1.1:53", "8.8.8.8:53"]
+
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`
+
+		path := createConfig(t, configContent)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, cfg.HTTP.DNSResolvers)
+		assert.Equal(t, 5*time.Second, cfg.HTTP.Timeout)
+	})
+
+	t.Run("invalid http dns_resolver address", func(t *testing.T) {
+		configContent := `
+[http]
 dns_resolvers = ["invalid-address"]
 
 [[notification]]
@@ -346,6 +881,556 @@ expected = 200
 		assert.Error(t, err)
 		assert.ErrorContains(t, err, "invalid http dns_resolver address")
 	})
+
+	jsonSchemaConfig := func(check string) string {
+		return `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "5s"
+
+[[services.check]]
+type = "json_schema"
+` + check
+	}
+
+	t.Run("json_schema compiles schema relative to config file", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`schema_file = "schemas/health.json"`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "schemas", "health.json"), `{"type": "object", "required": ["status"]}`)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+
+		check := cfg.Services[0].Check[0]
+		assert.NotNil(t, check.Schema)
+		assert.Equal(t, DEFAULT_SCHEMA_MAX_ERRORS, *check.SchemaMaxErrors)
+	})
+
+	t.Run("json_schema rejects zero schema_max_errors", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`
+schema_file = "schema.json"
+schema_max_errors = 0
+`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "schema.json"), `{"type": "object"}`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "field schema_max_errors must be greater than 0")
+	})
+
+	t.Run("json_schema requires schema_file", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`schema_max_errors = 3`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "field schema_file must be non-empty string")
+	})
+
+	t.Run("json_schema with missing schema file", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`schema_file = "missing.json"`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "failed compile json schema missing.json")
+	})
+
+	t.Run("json_schema with invalid schema", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`schema_file = "schema.json"`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "schema.json"), `{"type": "unknown"}`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "failed compile json schema schema.json")
+	})
+	tlsCertificateConfig := func(check string) string {
+		return `
+[[notification]]
+type = "webhook"
+services = ["svc"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "svc"
+url = "https://example.ru"
+interval = "5s"
+tls_skip_verify = true
+
+[[services.check]]
+type = "tls_certificate"
+` + check
+	}
+
+	t.Run("tls_certificate loads ca_file relative to config file", func(t *testing.T) {
+		path := createConfig(t, tlsCertificateConfig(`
+ca_file = "ca.pem"
+severities = { weak_key = "crit", intermediate_expiry = "off" }
+`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "ca.pem"), string(testCertificatePEM(t)))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.True(t, cfg.Services[0].TLSSkipVerify)
+		assert.NotNil(t, cfg.Services[0].Check[0].CAPool)
+	})
+
+	t.Run("tls_certificate requires https url", func(t *testing.T) {
+		path := createConfig(t, `
+[[services]]
+name = "site"
+url = "http://example.ru/"
+interval = "5s"
+
+[[services.check]]
+type = "tls_certificate"
+`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type tls_certificate requires https url")
+	})
+
+	t.Run("tls_certificate with ca_file without certificates", func(t *testing.T) {
+		path := createConfig(t, tlsCertificateConfig(`ca_file = "ca.pem"`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "ca.pem"), "not a certificate")
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "ca bundle ca.pem contains no pem certificates")
+	})
+
+	tlsServiceConfig := func(service string) string {
+		return `
+[[notification]]
+type = "webhook"
+services = ["mail"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "mail"
+type = "tls"
+interval = "5s"
+` + service
+	}
+
+	t.Run("tls service with starttls", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru:587"
+starttls = "smtp"
+
+[[services.check]]
+type = "ssl_not_expired"
+warn_days = 14
+crit_days = 7
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SERVICE_TYPE_TLS, cfg.Services[0].Type)
+		assert.Equal(t, "mail.example.ru:587", cfg.Services[0].Address)
+		assert.Equal(t, STARTTLS_SMTP, cfg.Services[0].StartTLS)
+	})
+
+	t.Run("service type defaults to http", func(t *testing.T) {
+		path := createConfig(t, jsonSchemaConfig(`schema_file = "schema.json"`))
+		writeTestFile(t, filepath.Join(filepath.Dir(path), "schema.json"), `{"type": "object"}`)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SERVICE_TYPE_HTTP, cfg.Services[0].Type)
+	})
+
+	t.Run("tls service requires address with port", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru"
+
+[[services.check]]
+type = "max_latency"
+max_latency_ms = 500
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service address must be host:port")
+	})
+
+	t.Run("tls service with tls_skip_verify", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru:465"
+tls_skip_verify = true
+
+[[services.check]]
+type = "tls_certificate"
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.True(t, cfg.Services[0].TLSSkipVerify)
+	})
+
+	t.Run("tls service with unknown starttls", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru:25"
+starttls = "xmpp"
+
+[[services.check]]
+type = "max_latency"
+max_latency_ms = 500
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service starttls must be one of")
+	})
+
+	t.Run("tls service rejects http checks", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru:465"
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type status_code is not supported by service type tls")
+	})
+
+	t.Run("tls service with tls_policy defaults", func(t *testing.T) {
+		path := createConfig(t, tlsServiceConfig(`
+address = "mail.example.ru:465"
+
+[[services.check]]
+type = "tls_policy"
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, DEFAULT_MIN_TLS_VERSION, cfg.Services[0].Check[0].MinTLSVersion)
+		assert.Equal(t, DEFAULT_TLS_PROBE_INTERVAL, cfg.Services[0].Check[0].ProbeInterval)
+	})
+
+	t.Run("tls_policy requires https url", func(t *testing.T) {
+		path := createConfig(t, `
+[[services]]
+name = "site"
+url = "http://example.ru/"
+interval = "5s"
+
+[[services.check]]
+type = "tls_policy"
+`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type tls_policy requires https url")
+	})
+}
+
+func TestCreateConfig_TCPService(t *testing.T) {
+	tcpServiceConfig := func(service string) string {
+		return `
+[[notification]]
+type = "webhook"
+services = ["redis"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "redis"
+type = "tcp"
+interval = "5s"
+` + service
+	}
+
+	t.Run("tls_skip_verify is rejected for tcp service", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`
+address = "redis.example.ru:6379"
+tls_skip_verify = true
+
+[[services.check]]
+type = "max_latency"
+max_latency_ms = 100
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service tls_skip_verify is supported only by http and tls services")
+	})
+
+	t.Run("tcp service with payload and expect", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`
+address = "redis.example.ru:6379"
+send = "PING\r\n"
+expect = '^\+PONG'
+
+[[services.check]]
+type = "max_latency"
+max_latency_ms = 100
+
+[[services.check]]
+type = "body_not_contains"
+substrings = ["NOAUTH"]
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SERVICE_TYPE_TCP, cfg.Services[0].Type)
+		assert.Equal(t, "PING\r\n", cfg.Services[0].Send)
+		assert.Equal(t, `^\+PONG`, cfg.Services[0].Expect)
+	})
+
+	t.Run("tcp service requires address with port", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`address = "redis.example.ru"`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service address must be host:port")
+	})
+
+	t.Run("tcp service with invalid expect", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`
+address = "redis.example.ru:6379"
+expect = "(PONG"
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service expect is invalid regexp")
+	})
+
+	t.Run("tcp body checks require expect", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`
+address = "redis.example.ru:6379"
+
+[[services.check]]
+type = "body_contains"
+substrings = ["PONG"]
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type body_contains requires service expect")
+	})
+
+	t.Run("tcp service rejects http checks", func(t *testing.T) {
+		path := createConfig(t, tcpServiceConfig(`
+address = "redis.example.ru:6379"
+
+[[services.check]]
+type = "ssl_not_expired"
+warn_days = 14
+crit_days = 7
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type ssl_not_expired is not supported by service type tcp")
+	})
+}
+
+func TestCreateConfig_DNSService(t *testing.T) {
+	dnsServiceConfig := func(service string) string {
+		return `
+[[notification]]
+type = "webhook"
+services = ["site-dns"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "site-dns"
+type = "dns"
+interval = "5s"
+` + service
+	}
+
+	t.Run("dns service with normalized values", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "Example.ru."
+record_type = "mx"
+resolvers = ["127.0.0.1:53"]
+
+[[services.check]]
+type = "dns_values"
+values = ["10 MX.example.ru."]
+exact = true
+
+[[services.check]]
+type = "dns_ttl"
+min_ttl = "5m"
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "example.ru", cfg.Services[0].Host)
+		assert.Equal(t, DNS_RECORD_MX, cfg.Services[0].RecordType)
+		assert.Equal(t, StringList{"10 mx.example.ru"}, cfg.Services[0].Check[0].Values)
+		assert.Equal(t, 5*time.Minute, cfg.Services[0].Check[1].MinTTL)
+		assert.Equal(t, SEVERITY_WARN, cfg.Services[0].Check[1].Severity)
+	})
+
+	t.Run("dns service uses http dns_resolvers", func(t *testing.T) {
+		path := createConfig(t, `
+[http]
+dns_resolvers = ["127.0.0.1:53"]
+`+dnsServiceConfig(`
+host = "example.ru"
+
+[[services.check]]
+type = "dns_count"
+min_count = 2
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, DNS_RECORD_A, cfg.Services[0].RecordType)
+		assert.Equal(t, []string{"127.0.0.1:53"}, cfg.Services[0].Resolvers)
+	})
+
+	t.Run("dns service requires resolvers", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "example.ru"
+
+[[services.check]]
+type = "dns_count"
+min_count = 1
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service(site-dns) requires resolvers or http dns_resolvers")
+	})
+
+	t.Run("dns_consistency defaults from dns service", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "example.ru"
+record_type = "MX"
+resolvers = ["127.0.0.1:53", "127.0.0.2:53"]
+
+[[services.check]]
+type = "dns_consistency"
+`))
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"127.0.0.1:53", "127.0.0.2:53"}, cfg.Services[0].Check[0].Resolvers)
+		assert.Equal(t, DNS_RECORD_MX, cfg.Services[0].Check[0].RecordType)
+	})
+
+	t.Run("dns_consistency on http service uses http dns_resolvers", func(t *testing.T) {
+		path := createConfig(t, `
+[http]
+dns_resolvers = ["127.0.0.1:53", "127.0.0.2:53"]
+
+[[notification]]
+type = "webhook"
+services = ["site"]
+min_severity = "ok"
+url = "https://example.com/"
+
+[[services]]
+name = "site"
+url = "https://example.ru/"
+interval = "5s"
+
+[[services.check]]
+type = "dns_consistency"
+record_type = "aaaa"
+`)
+
+		cfg, err := CreateConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"127.0.0.1:53", "127.0.0.2:53"}, cfg.Services[0].Check[0].Resolvers)
+		assert.Equal(t, DNS_RECORD_AAAA, cfg.Services[0].Check[0].RecordType)
+	})
+
+	t.Run("http service rejects dns checks", func(t *testing.T) {
+		path := createConfig(t, `
+[[services]]
+name = "site"
+url = "https://example.ru/"
+interval = "5s"
+
+[[services.check]]
+type = "dns_count"
+min_count = 1
+`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type dns_count is not supported by service type http")
+	})
+
+	t.Run("dns_consistency rejects ip address", func(t *testing.T) {
+		path := createConfig(t, `
+[[services]]
+name = "redis"
+type = "tcp"
+address = "192.0.2.10:6379"
+interval = "5s"
+
+[[services.check]]
+type = "dns_consistency"
+`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type dns_consistency requires host name, got ip address 192.0.2.10")
+	})
+
+	t.Run("dns_consistency rejects url with ip address", func(t *testing.T) {
+		path := createConfig(t, `
+[[services]]
+name = "site"
+url = "https://[2001:db8::1]:8443/"
+interval = "5s"
+
+[[services.check]]
+type = "dns_consistency"
+`)
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type dns_consistency requires host name, got ip address 2001:db8::1")
+	})
+
+	t.Run("dns_consistency requires two resolvers", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "example.ru"
+resolvers = ["127.0.0.1:53"]
+
+[[services.check]]
+type = "dns_consistency"
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "at least 2 resolvers are required")
+	})
+
+	t.Run("dns service with unknown record type", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "example.ru"
+record_type = "SRV"
+resolvers = ["127.0.0.1:53"]
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "service record_type must be one of")
+	})
+
+	t.Run("dns service rejects http checks", func(t *testing.T) {
+		path := createConfig(t, dnsServiceConfig(`
+host = "example.ru"
+resolvers = ["127.0.0.1:53"]
+
+[[services.check]]
+type = "status_code"
+expected = 200
+`))
+
+		_, err := CreateConfig(path)
+		assert.ErrorContains(t, err, "check type status_code is not supported by service type dns")
+	})
+}
+
+func writeTestFile(t *testing.T, filePath string, content string) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("got error while create test file dir: %s", err)
+	}
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("got error while write test file: %s", err)
+	}
 }
 
 func createConfig(t *testing.T, content string) string {
@@ -357,3 +1442,26 @@ func createConfig(t *testing.T, content string) string {
 
 	return configPath
 }
+
+func testCertificatePEM(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("got error while generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("got error while create certificate: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+type Delivery struct {
+	MaxAttempts    int           `toml:"max_attempts"`
+	InitialBackoff time.Duration `toml:"initial_backoff"`
+	MaxBackoff     time.Duration `toml:"max_backoff"`
+
+	// outbox для недоставленных событий, пустое значение отключает сохранение на диск
+	OutboxDir      string `toml:"outbox_dir"`
+	DeadLetterFile string `toml:"dead_letter_file"`
+	MaxReplays     int    `toml:"max_replays"`
+}
+
+func prepareDelivery(delivery *Delivery) error {
+	if delivery.MaxAttempts == 0 {
+		delivery.MaxAttempts = 3
+	}
+
+	if delivery.InitialBackoff == 0 {
+		delivery.InitialBackoff = time.Second
+	}
+
+	if delivery.MaxBackoff == 0 {
+		delivery.MaxBackoff = 30 * time.Second
+	}
+
+	if delivery.MaxReplays == 0 {
+		delivery.MaxReplays = 5
+	}
+
+	if delivery.OutboxDir != "" && delivery.DeadLetterFile == "" {
+		delivery.DeadLetterFile = filepath.Join(delivery.OutboxDir, "dead-letter.jsonl")
+	}
+
+	if delivery.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be greater than 0")
+	}
+
+	if delivery.InitialBackoff < 0 || delivery.MaxBackoff < delivery.InitialBackoff {
+		return fmt.Errorf("max_backoff must be greater than or equal to initial_backoff")
+	}
+
+	if delivery.MaxReplays < 1 {
+		return fmt.Errorf("max_replays must be greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+const (
+	DNS_RECORD_A     = "A"
+	DNS_RECORD_AAAA  = "AAAA"
+	DNS_RECORD_CNAME = "CNAME"
+	DNS_RECORD_MX    = "MX"
+	DNS_RECORD_TXT   = "TXT"
+	DNS_RECORD_NS    = "NS"
+	DNS_RECORD_CAA   = "CAA"
+)
+
+const DEFAULT_DNS_RECORD_TYPE = DNS_RECORD_A
+
+var dnsRecordTypes = []string{DNS_RECORD_A, DNS_RECORD_AAAA, DNS_RECORD_CNAME, DNS_RECORD_MX, DNS_RECORD_TXT, DNS_RECORD_NS, DNS_RECORD_CAA}
+
+// NormalizeDNSValue приводит значение записи к виду для сравнения: адреса в каноническую форму,
+// имена в нижний регистр без точки в конце. TXT сравнивается как есть.
+//
+// Формат значений: MX — "10 mx.example.ru", CAA — `0 issue "letsencrypt.org"`, TXT — строки записи без кавычек подряд.
+func NormalizeDNSValue(recordType string, value string) string {
+	value = strings.TrimSpace(value)
+
+	switch recordType {
+	case DNS_RECORD_TXT:
+		return value
+	case DNS_RECORD_A, DNS_RECORD_AAAA:
+		if addr, err := netip.ParseAddr(value); err == nil {
+			return addr.String()
+		}
+	}
+
+	return strings.TrimSuffix(strings.ToLower(value), ".")
+}
+
+func prepareDNSService(service *Service) {
+	service.RecordType = strings.ToUpper(service.RecordType)
+	if service.RecordType == "" {
+		service.RecordType = DEFAULT_DNS_RECORD_TYPE
+	}
+
+	service.Host = NormalizeDNSValue(DNS_RECORD_NS, service.Host)
+
+	for idx := range service.Check {
+		for valueIdx, value := range service.Check[idx].Values {
+			service.Check[idx].Values[valueIdx] = NormalizeDNSValue(service.RecordType, value)
+		}
+	}
+}
+
+func validateDNSService(service *Service) error {
+	if service.Host == "" {
+		return fmt.Errorf("service host can`t be empty")
+	}
+
+	if !slices.Contains(dnsRecordTypes, service.RecordType) {
+		return fmt.Errorf("service record_type must be one of: %s", strings.Join(dnsRecordTypes, ", "))
+	}
+
+	for _, resolver := range service.Resolvers {
+		if _, err := net.ResolveUDPAddr("udp", resolver); err != nil {
+			return fmt.Errorf("invalid service resolver address '%s': %w", resolver, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareDNSResolvers сервисы dns без своих resolvers используют резолверы из [http].
+// Для dns_consistency заполняются резолверы и тип записи по умолчанию.
+func prepareDNSResolvers(config *AppConfig) error {
+	for _, service := range config.Services {
+		if service.Type == SERVICE_TYPE_DNS && len(service.Resolvers) == 0 {
+			if len(config.HTTP.DNSResolvers) == 0 {
+				return fmt.Errorf("service(%s) requires resolvers or http dns_resolvers", service.Name)
+			}
+
+			service.Resolvers = config.HTTP.DNSResolvers
+		}
+
+		for idx := range service.Check {
+			check := &service.Check[idx]
+			if check.Type != TYPE_DNS_CONSISTENCY {
+				continue
+			}
+
+			if len(check.Resolvers) == 0 {
+				check.Resolvers = config.HTTP.DNSResolvers
+				if service.Type == SERVICE_TYPE_DNS {
+					check.Resolvers = service.Resolvers
+				}
+			}
+
+			if len(check.Resolvers) < 2 {
+				return fmt.Errorf("found error in service(%s).check: %w", service.Name, ErrCheckConfigValidation{
+					checkType: TYPE_DNS_CONSISTENCY,
+					field:     "resolvers",
+					msg:       "at least 2 resolvers are required, set resolvers or http dns_resolvers",
+				})
+			}
+
+			if check.RecordType == "" {
+				check.RecordType = DEFAULT_DNS_RECORD_TYPE
+				if service.Type == SERVICE_TYPE_DNS {
+					check.RecordType = service.RecordType
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateDNSValues(check CheckConfig) []error {
+	if len(check.Values) == 0 || slices.Contains(check.Values, "") {
+		return []error{ErrCheckConfigValidation{
+			checkType: TYPE_DNS_VALUES,
+			field:     "values",
+			msg:       "must be non-empty strings",
+		}}
+	}
+
+	return nil
+}
+
+func validateDNSCount(check CheckConfig) []error {
+	if check.MinCount <= 0 {
+		return []error{ErrCheckConfigValidation{
+			checkType: TYPE_DNS_COUNT,
+			field:     "min_count",
+			msg:       "must be greater than 0",
+		}}
+	}
+
+	return nil
+}
+
+func prepareDNSTTL(check *CheckConfig) {
+	if check.Severity == "" {
+		check.Severity = SEVERITY_WARN
+	}
+}
+
+func validateDNSTTL(check CheckConfig) []error {
+	var errs []error
+
+	if check.MinTTL < 0 || check.MaxTTL < 0 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_DNS_TTL,
+			field:     "min_ttl|max_ttl",
+			msg:       "must not be negative",
+		})
+	}
+
+	if check.MinTTL == 0 && check.MaxTTL == 0 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_DNS_TTL,
+			field:     "min_ttl|max_ttl",
+			msg:       "at least one bound is required",
+		})
+	}
+
+	if check.MaxTTL > 0 && check.MinTTL > check.MaxTTL {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_DNS_TTL,
+			field:     "min_ttl|max_ttl",
+			msg:       "max_ttl must be greater than or equal to min_ttl",
+		})
+	}
+
+	if check.Severity != SEVERITY_WARN && check.Severity != SEVERITY_CRIT {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_DNS_TTL,
+			field:     "severity",
+			msg:       "must be one of: warn, crit",
+		})
+	}
+
+	return errs
+}
+
+func validateDNSConsistency(check CheckConfig) []error {
+	var errs []error
+
+	if check.RecordType != "" && !slices.Contains(dnsRecordTypes, check.RecordType) {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_DNS_CONSISTENCY,
+			field:     "record_type",
+			msg:       fmt.Sprintf("must be one of: %s", strings.Join(dnsRecordTypes, ", ")),
+		})
+	}
+
+	for _, resolver := range check.Resolvers {
+		if _, err := net.ResolveUDPAddr("udp", resolver); err != nil {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: TYPE_DNS_CONSISTENCY,
+				field:     "resolvers",
+				msg:       fmt.Sprintf("invalid address '%s': %s", resolver, err),
+			})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// операторы сравнения числа элементов совпадают с операторами json_field
+var htmlCountOperators = []string{JSON_OP_EQ, JSON_OP_NE, JSON_OP_LT, JSON_OP_LTE, JSON_OP_GT, JSON_OP_GTE}
+
+func validateHTMLSelector(check CheckConfig) []error {
+	var errs []error
+
+	fieldErr := func(field string, msg string) {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_HTML_SELECTOR,
+			field:     field,
+			msg:       msg,
+		})
+	}
+
+	if check.Selector == "" {
+		fieldErr("selector", "must be non-empty string")
+	} else if _, err := cascadia.Compile(check.Selector); err != nil {
+		fieldErr("selector", fmt.Sprintf("invalid css selector: %s", err))
+	}
+
+	if check.Count != nil && *check.Count < 0 {
+		fieldErr("count", "must be greater than or equal to 0")
+	}
+
+	if check.CountOperator != "" {
+		if check.Count == nil {
+			fieldErr("count_operator", "requires count")
+		} else if !slices.Contains(htmlCountOperators, check.CountOperator) {
+			fieldErr("count_operator", "must be one of: eq, ne, lt, lte, gt, gte")
+		}
+	}
+
+	if check.Text != "" && check.Pattern != "" {
+		fieldErr("text|pattern", "only one of text and pattern can be set")
+	}
+
+	if check.Pattern != "" {
+		if _, err := regexp.Compile(check.Pattern); err != nil {
+			fieldErr("pattern", fmt.Sprintf("invalid regexp: %s", err))
+		}
+	}
+
+	hasValueCheck := check.Text != "" || check.Pattern != ""
+	if check.Attribute != "" && !hasValueCheck {
+		fieldErr("attribute", "requires text or pattern")
+	}
+
+	if check.Negate && !hasValueCheck {
+		fieldErr("negate", "requires text or pattern")
+	}
+
+	return errs
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+)
+
+const (
+	JSON_OP_EQ         = "eq"
+	JSON_OP_NE         = "ne"
+	JSON_OP_LT         = "lt"
+	JSON_OP_LTE        = "lte"
+	JSON_OP_GT         = "gt"
+	JSON_OP_GTE        = "gte"
+	JSON_OP_IN         = "in"
+	JSON_OP_REGEX      = "regex"
+	JSON_OP_EXISTS     = "exists"
+	JSON_OP_NOT_EXISTS = "not_exists"
+	JSON_OP_LEN_EQ     = "len_eq"
+	JSON_OP_LEN_GT     = "len_gt"
+
+	// JSON_CONTENT_TYPE_ANY отключает проверку заголовка Content-Type
+	JSON_CONTENT_TYPE_ANY = "any"
+)
+
+func validateJSONField(check CheckConfig) []error {
+	var errs []error
+
+	fieldErr := func(field string, msg string) {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_JSON_FIELD,
+			field:     field,
+			msg:       msg,
+		})
+	}
+
+	if check.JsonPath == "" {
+		fieldErr("json_path", "must be non-empty string")
+	}
+
+	if check.JsonContentType != "" && check.JsonContentType != JSON_CONTENT_TYPE_ANY {
+		if _, _, err := mime.ParseMediaType(check.JsonContentType); err != nil {
+			fieldErr("json_content_type", fmt.Sprintf("invalid media type: %s", err))
+		}
+	}
+
+	operator := check.JsonOperator
+	if operator == "" {
+		operator = JSON_OP_EQ
+	}
+
+	if operator == JSON_OP_EXISTS || operator == JSON_OP_NOT_EXISTS {
+		if check.JsonExpected != nil || check.JsonWarnExpected != nil {
+			fieldErr("json_expected", fmt.Sprintf("must be empty for operator %s", operator))
+		}
+		return errs
+	}
+
+	if check.JsonExpected == nil {
+		fieldErr("json_expected", "must be non-empty")
+		return errs
+	}
+
+	if err := validateJSONOperand(operator, check.JsonExpected); err != nil {
+		fieldErr("json_expected", err.Error())
+	}
+
+	if check.JsonWarnExpected != nil {
+		if err := validateJSONOperand(operator, check.JsonWarnExpected); err != nil {
+			fieldErr("json_warn_expected", err.Error())
+		}
+	}
+
+	return errs
+}
+
+func validateJSONOperand(operator string, operand any) error {
+	switch operator {
+	case JSON_OP_EQ, JSON_OP_NE:
+		return nil
+	case JSON_OP_LT, JSON_OP_LTE, JSON_OP_GT, JSON_OP_GTE:
+		switch operand.(type) {
+		case int64, float64:
+			return nil
+		}
+		return fmt.Errorf("must be a number for operator %s", operator)
+	case JSON_OP_IN:
+		if _, ok := operand.([]any); !ok {
+			return fmt.Errorf("must be an array for operator %s", operator)
+		}
+		return nil
+	case JSON_OP_REGEX:
+		pattern, ok := operand.(string)
+		if !ok {
+			return fmt.Errorf("must be a string for operator %s", operator)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regexp: %s", err)
+		}
+		return nil
+	case JSON_OP_LEN_EQ, JSON_OP_LEN_GT:
+		if length, ok := operand.(int64); !ok || length < 0 {
+			return fmt.Errorf("must be a non-negative integer for operator %s", operator)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown operator %s", operator)
+}
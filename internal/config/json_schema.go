@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// DEFAULT_SCHEMA_MAX_ERRORS сколько нарушений схемы попадает в сообщение, если schema_max_errors не задан
+const DEFAULT_SCHEMA_MAX_ERRORS = 5
+
+func validateJSONSchema(check CheckConfig) []error {
+	var errs []error
+
+	if check.SchemaFile == "" {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_JSON_SCHEMA,
+			field:     "schema_file",
+			msg:       "must be non-empty string",
+		})
+	}
+
+	if check.SchemaMaxErrors != nil && *check.SchemaMaxErrors < 1 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_JSON_SCHEMA,
+			field:     "schema_max_errors",
+			msg:       "must be greater than 0",
+		})
+	}
+
+	return errs
+}
+
+// prepareJSONSchemas компилирует схемы проверок json_schema, относительные пути считаются от каталога конфига.
+// Скомпилированные схемы кешируются по пути, чтобы шаблон, подключённый к нескольким сервисам, не разбирался повторно.
+func prepareJSONSchemas(checks []CheckConfig, baseDir string, compiled map[string]*jsonschema.Schema) error {
+	for idx := range checks {
+		check := &checks[idx]
+		if check.Type != TYPE_JSON_SCHEMA {
+			continue
+		}
+
+		if check.SchemaMaxErrors == nil {
+			check.SchemaMaxErrors = ptr(DEFAULT_SCHEMA_MAX_ERRORS)
+		}
+
+		schemaPath := check.SchemaFile
+		if !filepath.IsAbs(schemaPath) {
+			schemaPath = filepath.Join(baseDir, schemaPath)
+		}
+
+		if schema, ok := compiled[schemaPath]; ok {
+			check.Schema = schema
+			continue
+		}
+
+		schema, err := jsonschema.NewCompiler().Compile(schemaPath)
+		if err != nil {
+			return fmt.Errorf("failed compile json schema %s: %w", check.SchemaFile, err)
+		}
+
+		compiled[schemaPath] = schema
+		check.Schema = schema
+	}
+
+	return nil
+}
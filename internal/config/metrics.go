@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+type Metrics struct {
+	// адрес для /metrics, например ":9090"; пустое значение отключает метрики
+	Listen string `toml:"listen"`
+}
+
+// Status страница состояния и json api. Может слушать тот же адрес, что и метрики.
+type Status struct {
+	// адрес, например ":8080"; пустое значение отключает страницу
+	Listen string `toml:"listen"`
+}
+
+func validateListen(listen string) error {
+	if listen == "" {
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(listen); err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", listen, err)
+	}
+
+	return nil
+}
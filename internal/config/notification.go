@@ -1,17 +1,30 @@
 package config
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
 const (
-	NOTIFIER_TYPE_WEBHOOK = "webhook"
-	NOTIFIER_TYPE_EMAIL   = "email"
+	NOTIFIER_TYPE_WEBHOOK  = "webhook"
+	NOTIFIER_TYPE_EMAIL    = "email"
+	NOTIFIER_TYPE_TELEGRAM = "telegram"
+)
+
+const (
+	TELEGRAM_PARSE_MODE_HTML        = "HTML"
+	TELEGRAM_PARSE_MODE_MARKDOWN_V2 = "MarkdownV2"
+
+	TELEGRAM_DEFAULT_API_URL = "https://api.telegram.org"
 )
 
 type Notification struct {
+	Name               string        `toml:"name"` // по умолчанию из типа и адресата, используется как ключ в outbox
 	ServiceNames       []string      `toml:"services"`
 	MinSeverity        string        `toml:"min_severity"`
 	OnlyOnStatusChange bool          `toml:"only_on_status_change"`
@@ -19,20 +32,56 @@ type Notification struct {
 	Type               string        `toml:"type"` // webhook, telegram, email
 	RepeatInterval     time.Duration `toml:"repeat_interval"`
 
-	URL string `toml:"url"` // webhook
+	// очередь доставки
+	Timeout   time.Duration `toml:"timeout"` // ограничение на одну попытку отправки
+	QueueSize int           `toml:"queue_size"`
+	Workers   int           `toml:"workers"`
+
+	// webhook
+	URL     string            `toml:"url"`
+	Method  string            `toml:"method"`
+	Headers map[string]string `toml:"headers"`
 
 	// telegram
-	BotToken string `toml:"bot_token"`
-	ChatId   string `toml:"chat_id"`
+	BotToken  string `toml:"bot_token"`
+	ChatId    string `toml:"chat_id"`
+	APIURL    string `toml:"api_url"`
+	ParseMode string `toml:"parse_mode"`
 
 	EmailTo []string `toml:"email_to"` // email
 }
 
+// defaultNotificationName "<type>-<хэш адресата>": имя не зависит от порядка блоков в конфиге,
+// поэтому события в outbox после перестановки или добавления получателей уходят тому же адресату.
+func defaultNotificationName(notification Notification) string {
+	var target []string
+	switch notification.Type {
+	case NOTIFIER_TYPE_WEBHOOK:
+		target = []string{notification.URL}
+	case NOTIFIER_TYPE_TELEGRAM:
+		target = []string{notification.ChatId}
+	case NOTIFIER_TYPE_EMAIL:
+		target = slices.Sorted(slices.Values(notification.EmailTo))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(target, "\n")))
+
+	return fmt.Sprintf("%s-%x", notification.Type, sum[:4])
+}
+
 func validateNotification(notification Notification) error {
 	if len(notification.ServiceNames) == 0 || notification.ServiceNames[0] == "" {
 		return fmt.Errorf("notifications not linked to any services")
 	}
 
+	if notification.Timeout < 0 {
+		return fmt.Errorf("timeout can`t be negative")
+	}
+
+	if notification.QueueSize < 0 || notification.Workers < 0 {
+		return fmt.Errorf("queue_size and workers can`t be negative")
+	}
+
 	switch notification.Type {
 	case NOTIFIER_TYPE_EMAIL:
 		if len(notification.EmailTo) == 0 || notification.EmailTo[0] == "" {
@@ -51,6 +100,39 @@ func validateNotification(notification Notification) error {
 		if urlInfo.Hostname() == "" {
 			return fmt.Errorf("empty host")
 		}
+
+		switch notification.Method {
+		case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return fmt.Errorf("unsupported webhook method: %s", notification.Method)
+		}
+
+	case NOTIFIER_TYPE_TELEGRAM:
+		if notification.BotToken == "" {
+			return fmt.Errorf("empty bot_token for telegram notifier")
+		}
+
+		if notification.ChatId == "" {
+			return fmt.Errorf("empty chat_id for telegram notifier")
+		}
+
+		if notification.APIURL != "" {
+			urlInfo, err := url.Parse(notification.APIURL)
+			if err != nil {
+				return fmt.Errorf("invalid telegram api_url: %w", err)
+			}
+
+			if urlInfo.Hostname() == "" {
+				return fmt.Errorf("empty host in telegram api_url")
+			}
+		}
+
+		switch notification.ParseMode {
+		case "", TELEGRAM_PARSE_MODE_HTML, TELEGRAM_PARSE_MODE_MARKDOWN_V2:
+		default:
+			return fmt.Errorf("unsupported telegram parse_mode: %s", notification.ParseMode)
+		}
+
 	default:
 		return fmt.Errorf("unknown notifier: %s", notification.Type)
 	}
@@ -89,6 +89,83 @@ func TestValidateNotification(t *testing.T) {
 			},
 			hasError: false,
 		},
+		{
+			name: "check webhook type with unsupported method",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "webhook",
+				URL:          "http://example.com/hook",
+				Method:       "GET",
+			},
+			hasError:          true,
+			errorTextContains: "unsupported webhook method",
+		},
+		{
+			name: "check webhook type with put method",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "webhook",
+				URL:          "http://example.com/hook",
+				Method:       "PUT",
+			},
+			hasError: false,
+		},
+		{
+			name: "check telegram type with empty bot_token",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "telegram",
+				ChatId:       "-100500",
+			},
+			hasError:          true,
+			errorTextContains: "empty bot_token",
+		},
+		{
+			name: "check telegram type with empty chat_id",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "telegram",
+				BotToken:     "123:token",
+			},
+			hasError:          true,
+			errorTextContains: "empty chat_id",
+		},
+		{
+			name: "check telegram type with invalid api_url",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "telegram",
+				BotToken:     "123:token",
+				ChatId:       "-100500",
+				APIURL:       "tg-mirror.local",
+			},
+			hasError:          true,
+			errorTextContains: "empty host in telegram api_url",
+		},
+		{
+			name: "check telegram type with unsupported parse_mode",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "telegram",
+				BotToken:     "123:token",
+				ChatId:       "-100500",
+				ParseMode:    "Markdown",
+			},
+			hasError:          true,
+			errorTextContains: "unsupported telegram parse_mode",
+		},
+		{
+			name: "check telegram type success",
+			cfg: Notification{
+				ServiceNames: []string{"test"},
+				Type:         "telegram",
+				BotToken:     "123:token",
+				ChatId:       "-100500",
+				APIURL:       "https://tg-mirror.example.ru",
+				ParseMode:    "MarkdownV2",
+			},
+			hasError: false,
+		},
 		{
 			name: "check email type with empty receiver #1",
 			cfg: Notification{
@@ -132,3 +209,20 @@ func TestValidateNotification(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultNotificationName(t *testing.T) {
+	t.Run("name does not depend on position and order of recipients", func(t *testing.T) {
+		first := defaultNotificationName(Notification{Type: NOTIFIER_TYPE_EMAIL, EmailTo: []string{"a@example.ru", "b@example.ru"}})
+		second := defaultNotificationName(Notification{Type: NOTIFIER_TYPE_EMAIL, EmailTo: []string{"b@example.ru", "a@example.ru"}})
+
+		assert.Equal(t, first, second)
+		assert.Regexp(t, `^email-[0-9a-f]{8}$`, first)
+	})
+
+	t.Run("different targets get different names", func(t *testing.T) {
+		assert.NotEqual(t,
+			defaultNotificationName(Notification{Type: NOTIFIER_TYPE_TELEGRAM, ChatId: "-100"}),
+			defaultNotificationName(Notification{Type: NOTIFIER_TYPE_TELEGRAM, ChatId: "-200"}),
+		)
+	})
+}
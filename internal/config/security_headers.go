@@ -0,0 +1,53 @@
+package config
+
+// пункты проверки security_headers, ключи для severities
+const (
+	SECURITY_HEADER_HSTS                 = "hsts"
+	SECURITY_HEADER_CSP                  = "csp"
+	SECURITY_HEADER_CONTENT_TYPE_OPTIONS = "content_type_options"
+	SECURITY_HEADER_FRAME_OPTIONS        = "frame_options"
+	SECURITY_HEADER_REFERRER_POLICY      = "referrer_policy"
+	SECURITY_HEADER_VERSION_LEAK         = "version_leak"
+)
+
+// DEFAULT_HSTS_MIN_MAX_AGE полгода, как в рекомендациях Mozilla
+const DEFAULT_HSTS_MIN_MAX_AGE = 15768000
+
+var SecurityHeaderItems = []string{
+	SECURITY_HEADER_HSTS,
+	SECURITY_HEADER_CSP,
+	SECURITY_HEADER_CONTENT_TYPE_OPTIONS,
+	SECURITY_HEADER_FRAME_OPTIONS,
+	SECURITY_HEADER_REFERRER_POLICY,
+	SECURITY_HEADER_VERSION_LEAK,
+}
+
+func prepareSecurityHeaders(check *CheckConfig) {
+	if check.HSTSMinMaxAge == 0 {
+		check.HSTSMinMaxAge = DEFAULT_HSTS_MIN_MAX_AGE
+	}
+}
+
+// SecurityHeaderDefaults по умолчанию каждый пункт — warn.
+func SecurityHeaderDefaults() map[string]string {
+	result := make(map[string]string, len(SecurityHeaderItems))
+	for _, item := range SecurityHeaderItems {
+		result[item] = SEVERITY_WARN
+	}
+
+	return result
+}
+
+func validateSecurityHeaders(check CheckConfig) []error {
+	errs := validateItemSeverities(TYPE_SECURITY_HEADERS, check.Severities, SecurityHeaderDefaults())
+
+	if check.HSTSMinMaxAge < 0 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_SECURITY_HEADERS,
+			field:     "hsts_min_max_age",
+			msg:       "must be greater than or equal to 0",
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+)
+
+const (
+	SERVICE_TYPE_HTTP = "http"
+	SERVICE_TYPE_TLS  = "tls"
+	SERVICE_TYPE_TCP  = "tcp"
+	SERVICE_TYPE_DNS  = "dns"
+)
+
+const (
+	STARTTLS_SMTP     = "smtp"
+	STARTTLS_IMAP     = "imap"
+	STARTTLS_POP3     = "pop3"
+	STARTTLS_FTP      = "ftp"
+	STARTTLS_POSTGRES = "postgres"
+)
+
+// serviceTypeChecks проверки, доступные каждому типу сервиса
+var serviceTypeChecks = map[string][]string{
+	SERVICE_TYPE_HTTP: {
+		TYPE_STATUS_CODE, TYPE_BODY_CONTAINS, TYPE_BODY_NOT_CONTAINS, TYPE_BODY_REGEX, TYPE_BODY_NOT_REGEX,
+		TYPE_SSL_NOT_EXPIRED, TYPE_JSON_FIELD, TYPE_JSON_SCHEMA, TYPE_HTML_SELECTOR, TYPE_MAX_LATENCY, TYPE_HEADER,
+		TYPE_SECURITY_HEADERS, TYPE_TLS_CERTIFICATE, TYPE_TLS_POLICY, TYPE_DNS_CONSISTENCY,
+	},
+	SERVICE_TYPE_TLS: {TYPE_SSL_NOT_EXPIRED, TYPE_TLS_CERTIFICATE, TYPE_TLS_POLICY, TYPE_DNS_CONSISTENCY, TYPE_MAX_LATENCY},
+	SERVICE_TYPE_TCP: {TYPE_MAX_LATENCY, TYPE_DNS_CONSISTENCY, TYPE_BODY_CONTAINS, TYPE_BODY_NOT_CONTAINS, TYPE_BODY_REGEX, TYPE_BODY_NOT_REGEX},
+	SERVICE_TYPE_DNS: {TYPE_DNS_VALUES, TYPE_DNS_COUNT, TYPE_DNS_TTL, TYPE_DNS_CONSISTENCY, TYPE_MAX_LATENCY},
+}
+
+func prepareServiceType(service *Service) {
+	if service.Type == "" {
+		service.Type = SERVICE_TYPE_HTTP
+	}
+
+	if service.Type == SERVICE_TYPE_DNS {
+		prepareDNSService(service)
+	}
+}
+
+// validateServiceTarget адрес проверки зависит от типа сервиса: url для http, address для остальных.
+func validateServiceTarget(service *Service) error {
+	if service.TLSSkipVerify && service.Type != SERVICE_TYPE_HTTP && service.Type != SERVICE_TYPE_TLS {
+		return fmt.Errorf("service tls_skip_verify is supported only by http and tls services")
+	}
+
+	switch service.Type {
+	case SERVICE_TYPE_HTTP:
+		if service.URL == "" {
+			return fmt.Errorf("service url can`t be empty")
+		}
+	case SERVICE_TYPE_TLS:
+		if _, _, err := net.SplitHostPort(service.Address); err != nil {
+			return fmt.Errorf("service address must be host:port: %w", err)
+		}
+
+		starttls := []string{STARTTLS_SMTP, STARTTLS_IMAP, STARTTLS_POP3, STARTTLS_FTP, STARTTLS_POSTGRES}
+		if service.StartTLS != "" && !slices.Contains(starttls, service.StartTLS) {
+			return fmt.Errorf("service starttls must be one of: smtp, imap, pop3, ftp, postgres")
+		}
+	case SERVICE_TYPE_TCP:
+		if _, _, err := net.SplitHostPort(service.Address); err != nil {
+			return fmt.Errorf("service address must be host:port: %w", err)
+		}
+
+		if _, err := regexp.Compile(service.Expect); err != nil {
+			return fmt.Errorf("service expect is invalid regexp: %w", err)
+		}
+	case SERVICE_TYPE_DNS:
+		return validateDNSService(service)
+	default:
+		return fmt.Errorf("unknown service type: %s", service.Type)
+	}
+
+	return nil
+}
+
+func validateServiceChecks(service *Service) error {
+	if service.Type == SERVICE_TYPE_HTTP {
+		serviceURL, err := url.Parse(service.URL)
+		isHTTPS := err == nil && serviceURL.Scheme == "https" && serviceURL.Hostname() != ""
+
+		for _, check := range service.Check {
+			switch check.Type {
+			case TYPE_TLS_CERTIFICATE, TYPE_TLS_POLICY:
+				if !isHTTPS {
+					return fmt.Errorf("check type %s requires https url", check.Type)
+				}
+			}
+		}
+	}
+
+	for _, check := range service.Check {
+		if check.Type == TYPE_DNS_CONSISTENCY {
+			if err := validateConsistencyHost(service); err != nil {
+				return fmt.Errorf("check type %s %w", check.Type, err)
+			}
+		}
+	}
+
+	supported, ok := serviceTypeChecks[service.Type]
+	if !ok {
+		return nil
+	}
+
+	for _, check := range service.Check {
+		if !slices.Contains(supported, check.Type) {
+			return fmt.Errorf("check type %s is not supported by service type %s", check.Type, service.Type)
+		}
+
+		// без expect ответ не читается, проверять в нём нечего
+		bodyChecks := []string{TYPE_BODY_CONTAINS, TYPE_BODY_NOT_CONTAINS, TYPE_BODY_REGEX, TYPE_BODY_NOT_REGEX}
+		if service.Type == SERVICE_TYPE_TCP && slices.Contains(bodyChecks, check.Type) && service.Expect == "" {
+			return fmt.Errorf("check type %s requires service expect", check.Type)
+		}
+	}
+
+	return nil
+}
+
+// validateConsistencyHost dns_consistency запрашивает имя из url или address, по IP-адресу запрашивать нечего.
+func validateConsistencyHost(service *Service) error {
+	var host string
+	switch service.Type {
+	case SERVICE_TYPE_DNS:
+		return nil
+	case SERVICE_TYPE_TLS, SERVICE_TYPE_TCP:
+		addressHost, _, err := net.SplitHostPort(service.Address)
+		if err != nil {
+			return fmt.Errorf("requires address host:port: %w", err)
+		}
+		host = addressHost
+	default:
+		serviceURL, err := url.Parse(service.URL)
+		if err != nil || serviceURL.Hostname() == "" {
+			return fmt.Errorf("requires url with host")
+		}
+		host = serviceURL.Hostname()
+	}
+
+	if net.ParseIP(host) != nil {
+		return fmt.Errorf("requires host name, got ip address %s", host)
+	}
+
+	return nil
+}
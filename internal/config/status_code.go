@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	SEVERITY_WARN = "warn"
+	SEVERITY_CRIT = "crit"
+	// SEVERITY_OFF отключает пункт security_headers
+	SEVERITY_OFF = "off"
+)
+
+// StatusCodeRange диапазон кодов ответа включительно, одиночный код — диапазон из одного значения.
+type StatusCodeRange struct {
+	From int
+	To   int
+}
+
+func (r StatusCodeRange) String() string {
+	switch {
+	case r.From == r.To:
+		return strconv.Itoa(r.From)
+	case r.From%100 == 0 && r.To == r.From+99:
+		return fmt.Sprintf("%dxx", r.From/100)
+	}
+
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// StatusCodes ожидаемые коды ответа. В TOML задаётся числом, строкой ("204", "2xx", "200-299")
+// или массивом из них: expected = [200, 204, "3xx"].
+type StatusCodes []StatusCodeRange
+
+func (c *StatusCodes) UnmarshalTOML(value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		items = []any{value}
+	}
+
+	result := make(StatusCodes, 0, len(items))
+	for _, item := range items {
+		codeRange, err := parseStatusCodeRange(item)
+		if err != nil {
+			return err
+		}
+		result = append(result, codeRange)
+	}
+
+	*c = result
+
+	return nil
+}
+
+func parseStatusCodeRange(value any) (StatusCodeRange, error) {
+	switch v := value.(type) {
+	case int64:
+		return StatusCodeRange{From: int(v), To: int(v)}, nil
+	case string:
+		spec := strings.ToLower(strings.TrimSpace(v))
+
+		if class, ok := parseStatusClass(spec); ok {
+			return StatusCodeRange{From: class * 100, To: class*100 + 99}, nil
+		}
+
+		if from, to, ok := strings.Cut(spec, "-"); ok {
+			fromCode, fromErr := strconv.Atoi(strings.TrimSpace(from))
+			toCode, toErr := strconv.Atoi(strings.TrimSpace(to))
+			if fromErr != nil || toErr != nil {
+				return StatusCodeRange{}, fmt.Errorf("invalid status code range %q", v)
+			}
+			return StatusCodeRange{From: fromCode, To: toCode}, nil
+		}
+
+		code, err := strconv.Atoi(spec)
+		if err != nil {
+			return StatusCodeRange{}, fmt.Errorf("invalid status code %q, expected code, class like 2xx or range like 200-299", v)
+		}
+		return StatusCodeRange{From: code, To: code}, nil
+	}
+
+	return StatusCodeRange{}, fmt.Errorf("invalid status code %v, expected number or string", value)
+}
+
+// parseStatusClass разбирает класс кодов вида "5xx" и возвращает его первую цифру.
+func parseStatusClass(spec string) (int, bool) {
+	spec = strings.ToLower(spec)
+	if len(spec) != 3 || spec[1:] != "xx" || spec[0] < '1' || spec[0] > '5' {
+		return 0, false
+	}
+
+	return int(spec[0] - '0'), true
+}
+
+// ParseStatusClass возвращает первую цифру класса кодов вида "4xx".
+func ParseStatusClass(spec string) (int, error) {
+	class, ok := parseStatusClass(spec)
+	if !ok {
+		return 0, fmt.Errorf("invalid status class %q, expected 1xx-5xx", spec)
+	}
+
+	return class, nil
+}
+
+func validateStatusCodes(codes StatusCodes, severities map[string]string) []error {
+	var errs []error
+
+	if len(codes) == 0 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_STATUS_CODE,
+			field:     "expected",
+			msg:       "must be non-empty",
+		})
+	}
+
+	for _, codeRange := range codes {
+		if codeRange.From < 100 || codeRange.To > 599 || codeRange.From > codeRange.To {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: TYPE_STATUS_CODE,
+				field:     "expected",
+				msg:       fmt.Sprintf("invalid code or range %s, codes must be between 100 and 599", codeRange),
+			})
+		}
+	}
+
+	for class, severity := range severities {
+		if _, err := ParseStatusClass(class); err != nil {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: TYPE_STATUS_CODE,
+				field:     "severities",
+				msg:       err.Error(),
+			})
+		}
+
+		if severity != SEVERITY_WARN && severity != SEVERITY_CRIT {
+			errs = append(errs, ErrCheckConfigValidation{
+				checkType: TYPE_STATUS_CODE,
+				field:     "severities",
+				msg:       fmt.Sprintf("severity for %s must be one of: warn, crit", class),
+			})
+		}
+	}
+
+	return errs
+}
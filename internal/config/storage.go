@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type Storage struct {
+	// файл с состояниями сервисов, пустое значение — состояние хранится только в памяти
+	StateFile string `toml:"state_file"`
+
+	// каталог истории проверок, пустое значение отключает историю
+	HistoryDir string `toml:"history_dir"`
+	// сколько хранить записи: без параметра DEFAULT_HISTORY_RETENTION, 0 — хранить всё
+	HistoryRetention *time.Duration `toml:"history_retention"`
+}
+
+const DEFAULT_HISTORY_RETENTION = 30 * 24 * time.Hour
+
+func prepareStorage(storage *Storage) error {
+	if storage.HistoryRetention == nil {
+		storage.HistoryRetention = ptr(DEFAULT_HISTORY_RETENTION)
+	}
+
+	if *storage.HistoryRetention < 0 {
+		return fmt.Errorf("history_retention can`t be negative")
+	}
+
+	return nil
+}
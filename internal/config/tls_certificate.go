@@ -0,0 +1,73 @@
+package config
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// пункты проверки tls_certificate, ключи для severities
+const (
+	TLS_FINDING_HOSTNAME            = "hostname"
+	TLS_FINDING_EXPIRED             = "expired"
+	TLS_FINDING_NOT_YET_VALID       = "not_yet_valid"
+	TLS_FINDING_SELF_SIGNED         = "self_signed"
+	TLS_FINDING_UNTRUSTED_ROOT      = "untrusted_root"
+	TLS_FINDING_INCOMPLETE_CHAIN    = "incomplete_chain"
+	TLS_FINDING_WEAK_KEY            = "weak_key"
+	TLS_FINDING_WEAK_SIGNATURE      = "weak_signature"
+	TLS_FINDING_INTERMEDIATE_EXPIRY = "intermediate_expiry"
+)
+
+// TLSCertificateFindings пункты с уровнем по умолчанию
+var TLSCertificateFindings = map[string]string{
+	TLS_FINDING_HOSTNAME:            SEVERITY_CRIT,
+	TLS_FINDING_EXPIRED:             SEVERITY_CRIT,
+	TLS_FINDING_NOT_YET_VALID:       SEVERITY_CRIT,
+	TLS_FINDING_SELF_SIGNED:         SEVERITY_CRIT,
+	TLS_FINDING_UNTRUSTED_ROOT:      SEVERITY_CRIT,
+	TLS_FINDING_INCOMPLETE_CHAIN:    SEVERITY_CRIT,
+	TLS_FINDING_WEAK_KEY:            SEVERITY_WARN,
+	TLS_FINDING_WEAK_SIGNATURE:      SEVERITY_WARN,
+	TLS_FINDING_INTERMEDIATE_EXPIRY: SEVERITY_WARN,
+}
+
+func validateTLSCertificate(check CheckConfig) []error {
+	return validateItemSeverities(TYPE_TLS_CERTIFICATE, check.Severities, TLSCertificateFindings)
+}
+
+// prepareCABundles загружает ca_file проверок tls_certificate, относительные пути считаются от каталога конфига.
+func prepareCABundles(checks []CheckConfig, baseDir string, loaded map[string]*x509.CertPool) error {
+	for idx := range checks {
+		check := &checks[idx]
+		if check.Type != TYPE_TLS_CERTIFICATE || check.CAFile == "" {
+			continue
+		}
+
+		caPath := check.CAFile
+		if !filepath.IsAbs(caPath) {
+			caPath = filepath.Join(baseDir, caPath)
+		}
+
+		if pool, ok := loaded[caPath]; ok {
+			check.CAPool = pool
+			continue
+		}
+
+		data, err := os.ReadFile(caPath)
+		if err != nil {
+			return fmt.Errorf("failed read ca bundle %s: %w", check.CAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("ca bundle %s contains no pem certificates", check.CAFile)
+		}
+
+		loaded[caPath] = pool
+		check.CAPool = pool
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+)
+
+// пункты проверки tls_policy, ключи для severities
+const (
+	TLS_POLICY_INSECURE_VERSION = "insecure_version"
+	TLS_POLICY_WEAK_CIPHER      = "weak_cipher"
+)
+
+// TLSPolicyFindings пункты с уровнем по умолчанию
+var TLSPolicyFindings = map[string]string{
+	TLS_POLICY_INSECURE_VERSION: SEVERITY_CRIT,
+	TLS_POLICY_WEAK_CIPHER:      SEVERITY_WARN,
+}
+
+const (
+	DEFAULT_MIN_TLS_VERSION = "1.2"
+	// политика TLS меняется редко, пробы не повторяются на каждой проверке
+	DEFAULT_TLS_PROBE_INTERVAL = time.Hour
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion версия в виде "1.2".
+func ParseTLSVersion(version string) (uint16, error) {
+	result, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unknown tls version %s", version)
+	}
+
+	return result, nil
+}
+
+func prepareTLSPolicy(check *CheckConfig) {
+	if check.MinTLSVersion == "" {
+		check.MinTLSVersion = DEFAULT_MIN_TLS_VERSION
+	}
+
+	if check.ProbeInterval == 0 {
+		check.ProbeInterval = DEFAULT_TLS_PROBE_INTERVAL
+	}
+}
+
+func validateTLSPolicy(check CheckConfig) []error {
+	errs := validateItemSeverities(TYPE_TLS_POLICY, check.Severities, TLSPolicyFindings)
+
+	if _, err := ParseTLSVersion(check.MinTLSVersion); err != nil {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_TLS_POLICY,
+			field:     "min_tls_version",
+			msg:       "must be one of: 1.0, 1.1, 1.2, 1.3",
+		})
+	}
+
+	if check.ProbeInterval < 0 {
+		errs = append(errs, ErrCheckConfigValidation{
+			checkType: TYPE_TLS_POLICY,
+			field:     "probe_interval",
+			msg:       "can`t be negative",
+		})
+	}
+
+	return errs
+}
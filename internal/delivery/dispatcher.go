@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+var ErrDispatcherStopped = errors.New("dispatcher stopped")
+
+// Metrics получает состояние очередей диспетчера и исходы попыток отправки.
+type Metrics interface {
+	QueueDepth(notifier string, depth int)
+	Dropped(notifier string)
+	NotifySent(notifier string)
+	NotifyFailed(notifier string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) QueueDepth(notifier string, depth int) {}
+func (nopMetrics) Dropped(notifier string)               {}
+func (nopMetrics) NotifySent(notifier string)            {}
+func (nopMetrics) NotifyFailed(notifier string)          {}
+
+// LaneConfig параметры очереди одного получателя.
+type LaneConfig struct {
+	Name      string
+	QueueSize int
+	Workers   int
+}
+
+type lane struct {
+	name    string
+	jobs    chan *domain.AlertEvent
+	workers int
+}
+
+// NewDispatcher создаёт диспетчер с отдельной ограниченной очередью и своими воркерами на каждого
+// получателя, чтобы медленный получатель не задерживал проверки и остальных получателей.
+// metrics может быть nil.
+func NewDispatcher(queue *Queue, lanes []LaneConfig, metrics Metrics) *Dispatcher {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+
+	byName := make(map[string]*lane, len(lanes))
+	for _, cfg := range lanes {
+		byName[cfg.Name] = &lane{
+			name:    cfg.Name,
+			jobs:    make(chan *domain.AlertEvent, max(cfg.QueueSize, 1)),
+			workers: max(cfg.Workers, 1),
+		}
+	}
+
+	return &Dispatcher{
+		queue:   queue,
+		lanes:   byName,
+		metrics: metrics,
+	}
+}
+
+type Dispatcher struct {
+	queue   *Queue
+	lanes   map[string]*lane
+	metrics Metrics
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// Start запускает воркеры и переотправку событий из outbox.
+func (d *Dispatcher) Start() {
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	for _, l := range d.lanes {
+		for range l.workers {
+			d.wg.Add(1)
+			go d.worker(l)
+		}
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		if err := d.queue.Replay(d.ctx); err != nil {
+			slog.Error("failed replay outbox", "component", "dispatcher", "err", err)
+		}
+	}()
+}
+
+// Dispatch ставит событие в очередь получателя и не блокируется.
+// Если очередь переполнена, событие откладывается в outbox, а в метрики пишется потеря.
+func (d *Dispatcher) Dispatch(notifierName string, event *domain.AlertEvent) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	l, ok := d.lanes[notifierName]
+	if !ok {
+		return fmt.Errorf("unknown notifier: %s", notifierName)
+	}
+
+	if d.stopped {
+		return ErrDispatcherStopped
+	}
+
+	select {
+	case l.jobs <- event:
+		d.metrics.QueueDepth(l.name, len(l.jobs))
+		return nil
+	default:
+	}
+
+	d.metrics.Dropped(l.name)
+
+	err := fmt.Errorf("queue of notifier %s is full", notifierName)
+	if parkErr := d.queue.Park(context.Background(), notifierName, event, err); parkErr != nil {
+		return fmt.Errorf("%w; %w", err, parkErr)
+	}
+
+	return err
+}
+
+// Stop перестаёт принимать события и ждёт, пока воркеры разберут очереди.
+// Если ctx истёк раньше, отменяет текущие отправки, а оставшиеся в очередях события откладывает в outbox.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return ErrDispatcherStopped
+	}
+	d.stopped = true
+	for _, l := range d.lanes {
+		close(l.jobs)
+	}
+	d.mu.Unlock()
+
+	exit := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(exit)
+	}()
+
+	select {
+	case <-exit:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.cancel()
+
+	for _, l := range d.lanes {
+		for event := range l.jobs {
+			if err := d.queue.Park(context.Background(), l.name, event, ctx.Err()); err != nil {
+				slog.Error("failed park event on shutdown", "component", "dispatcher", "notifier", l.name, "service_name", event.ServiceName, "err", err)
+			}
+		}
+		d.metrics.QueueDepth(l.name, 0)
+	}
+
+	return ctx.Err()
+}
+
+func (d *Dispatcher) worker(l *lane) {
+	defer d.wg.Done()
+
+	logger := slog.With("component", "dispatcher_worker", "notifier", l.name)
+
+	for event := range l.jobs {
+		d.metrics.QueueDepth(l.name, len(l.jobs))
+
+		if err := d.queue.Deliver(d.ctx, l.name, event); err != nil {
+			logger.Error("failed deliver event", "service_name", event.ServiceName, "err", err)
+		}
+	}
+}
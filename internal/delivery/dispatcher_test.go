@@ -0,0 +1,191 @@
+package delivery
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingNotifier struct {
+	release chan struct{}
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, alert *domain.AlertEvent) error {
+	select {
+	case <-n.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type recordedMetrics struct {
+	mu      sync.Mutex
+	depth   map[string]int
+	dropped map[string]int
+	sent    map[string]int
+	failed  map[string]int
+}
+
+func newRecordedMetrics() *recordedMetrics {
+	return &recordedMetrics{
+		depth:   make(map[string]int),
+		dropped: make(map[string]int),
+		sent:    make(map[string]int),
+		failed:  make(map[string]int),
+	}
+}
+
+func (m *recordedMetrics) QueueDepth(notifier string, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.depth[notifier] = depth
+}
+
+func (m *recordedMetrics) Dropped(notifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropped[notifier]++
+}
+
+func (m *recordedMetrics) NotifySent(notifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[notifier]++
+}
+
+func (m *recordedMetrics) NotifyFailed(notifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed[notifier]++
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := newRecordedMetrics()
+	notifier := WithMetrics("email-0", &fakeNotifier{failures: 2}, metrics)
+
+	queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, nil, testPolicy)
+	err := queue.Deliver(t.Context(), "email-0", &domain.AlertEvent{ServiceName: "example.ru"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, metrics.failed["email-0"])
+	assert.Equal(t, 1, metrics.sent["email-0"])
+}
+
+func TestDispatcher(t *testing.T) {
+	event := &domain.AlertEvent{ServiceName: "example.ru", Status: domain.CRIT}
+
+	t.Run("медленный получатель не задерживает остальных", func(t *testing.T) {
+		slow := &blockingNotifier{release: make(chan struct{})}
+		fast := &fakeNotifier{}
+
+		queue := NewQueue([]domain.RoutedNotifier{
+			{Name: "email-0", Notifier: slow},
+			{Name: "webhook-1", Notifier: fast},
+		}, nil, testPolicy)
+		dispatcher := NewDispatcher(queue, []LaneConfig{
+			{Name: "email-0", QueueSize: 10, Workers: 1},
+			{Name: "webhook-1", QueueSize: 10, Workers: 1},
+		}, nil)
+		dispatcher.Start()
+
+		assert.NoError(t, dispatcher.Dispatch("email-0", event))
+		assert.NoError(t, dispatcher.Dispatch("webhook-1", event))
+
+		assert.Eventually(t, func() bool {
+			fast.mu.Lock()
+			defer fast.mu.Unlock()
+			return fast.calls == 1
+		}, time.Second, 5*time.Millisecond)
+
+		close(slow.release)
+		assert.NoError(t, dispatcher.Stop(t.Context()))
+	})
+
+	t.Run("переполненная очередь откладывает событие в outbox", func(t *testing.T) {
+		slow := &blockingNotifier{release: make(chan struct{})}
+		outbox := newMemoryOutbox()
+		metrics := newRecordedMetrics()
+
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: slow}}, outbox, testPolicy)
+		dispatcher := NewDispatcher(queue, []LaneConfig{{Name: "email-0", QueueSize: 1, Workers: 1}}, metrics)
+		dispatcher.Start()
+
+		// первое событие забирает воркер, второе занимает очередь, третье не помещается
+		require.NoError(t, dispatcher.Dispatch("email-0", event))
+		assert.Eventually(t, func() bool {
+			metrics.mu.Lock()
+			defer metrics.mu.Unlock()
+			return metrics.depth["email-0"] == 0
+		}, time.Second, 5*time.Millisecond)
+		require.NoError(t, dispatcher.Dispatch("email-0", event))
+
+		err := dispatcher.Dispatch("email-0", event)
+		assert.ErrorContains(t, err, "queue of notifier email-0 is full")
+		metrics.mu.Lock()
+		assert.Equal(t, 1, metrics.dropped["email-0"])
+		metrics.mu.Unlock()
+
+		close(slow.release)
+		assert.NoError(t, dispatcher.Stop(t.Context()))
+
+		entries, _ := outbox.List(t.Context())
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("остановка дожидается очереди", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, nil, testPolicy)
+		dispatcher := NewDispatcher(queue, []LaneConfig{{Name: "email-0", QueueSize: 10, Workers: 2}}, nil)
+		dispatcher.Start()
+
+		for range 5 {
+			require.NoError(t, dispatcher.Dispatch("email-0", event))
+		}
+
+		assert.NoError(t, dispatcher.Stop(t.Context()))
+		assert.Equal(t, 5, notifier.calls)
+		assert.ErrorIs(t, dispatcher.Dispatch("email-0", event), ErrDispatcherStopped)
+	})
+
+	t.Run("по истечении ctx остановки события уходят в outbox", func(t *testing.T) {
+		slow := &blockingNotifier{release: make(chan struct{})}
+		outbox := newMemoryOutbox()
+
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: slow}}, outbox, RetryPolicy{MaxAttempts: 1, MaxReplays: 1})
+		dispatcher := NewDispatcher(queue, []LaneConfig{{Name: "email-0", QueueSize: 10, Workers: 1}}, nil)
+		dispatcher.Start()
+
+		for range 3 {
+			require.NoError(t, dispatcher.Dispatch("email-0", event))
+		}
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, dispatcher.Stop(ctx), context.DeadlineExceeded)
+		assert.Eventually(t, func() bool {
+			entries, _ := outbox.List(t.Context())
+			return len(entries) == 3
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("неизвестный получатель", func(t *testing.T) {
+		dispatcher := NewDispatcher(NewQueue(nil, nil, testPolicy), nil, nil)
+
+		assert.ErrorContains(t, dispatcher.Dispatch("email-0", event), "unknown notifier")
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	notifier := WithTimeout(&blockingNotifier{release: make(chan struct{})}, 10*time.Millisecond)
+
+	err := notifier.Notify(t.Context(), &domain.AlertEvent{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
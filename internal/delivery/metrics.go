@@ -0,0 +1,37 @@
+package delivery
+
+import (
+	"context"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+// WithMetrics считает успешные и неудачные вызовы Notify, каждая попытка учитывается отдельно.
+func WithMetrics(name string, notifier domain.Notifier, metrics Metrics) domain.Notifier {
+	if metrics == nil {
+		return notifier
+	}
+
+	return &metricsNotifier{
+		name:     name,
+		notifier: notifier,
+		metrics:  metrics,
+	}
+}
+
+type metricsNotifier struct {
+	name     string
+	notifier domain.Notifier
+	metrics  Metrics
+}
+
+func (n *metricsNotifier) Notify(ctx context.Context, alert *domain.AlertEvent) error {
+	if err := n.notifier.Notify(ctx, alert); err != nil {
+		n.metrics.NotifyFailed(n.name)
+		return err
+	}
+
+	n.metrics.NotifySent(n.name)
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package delivery
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxReplays     int
+}
+
+// Backoff возвращает паузу перед попыткой attempt (нумерация с 1): initial, 2*initial, 4*initial, ... но не больше MaxBackoff.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// NewQueue создаёт очередь доставки. outbox может быть nil — тогда недоставленные события только логируются.
+func NewQueue(notifiers []domain.RoutedNotifier, outbox domain.Outbox, policy RetryPolicy) *Queue {
+	byName := make(map[string]domain.Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byName[notifier.Name] = notifier.Notifier
+	}
+
+	return &Queue{
+		notifiers: byName,
+		outbox:    outbox,
+		policy:    policy,
+	}
+}
+
+// Queue доставляет события с повторами по экспоненциальной задержке, а то, что доставить
+// не удалось, откладывает в outbox до следующего запуска.
+type Queue struct {
+	notifiers map[string]domain.Notifier
+	outbox    domain.Outbox
+	policy    RetryPolicy
+}
+
+func (q *Queue) Deliver(ctx context.Context, notifierName string, event *domain.AlertEvent) error {
+	notifier, ok := q.notifiers[notifierName]
+	if !ok {
+		return fmt.Errorf("unknown notifier: %s", notifierName)
+	}
+
+	err := q.send(ctx, notifier, event)
+	if err == nil {
+		return nil
+	}
+
+	if parkErr := q.Park(ctx, notifierName, event, err); parkErr != nil {
+		return fmt.Errorf("%w; %w", err, parkErr)
+	}
+
+	return err
+}
+
+// Park откладывает событие в outbox без попыток отправки, reason сохраняется как последняя ошибка.
+func (q *Queue) Park(ctx context.Context, notifierName string, event *domain.AlertEvent, reason error) error {
+	logger := slog.With("component", "delivery_queue", "notifier", notifierName, "service_name", event.ServiceName)
+
+	if q.outbox == nil {
+		logger.Error("event not delivered, outbox disabled", "err", reason)
+		return nil
+	}
+
+	logger.Warn("event not delivered, moving to outbox", "err", reason)
+
+	entry := domain.OutboxEntry{
+		ID:        newEntryID(),
+		Notifier:  notifierName,
+		Event:     *event,
+		LastError: reason.Error(),
+		CreatedAt: time.Now(),
+	}
+
+	// событие сохраняем даже если ctx уже отменён, иначе оно потеряется при остановке
+	if err := q.outbox.Put(context.WithoutCancel(ctx), entry); err != nil {
+		return fmt.Errorf("failed put to outbox: %w", err)
+	}
+
+	return nil
+}
+
+// Replay переотправляет события из outbox. Успешно доставленные удаляются, а события,
+// которые не удалось доставить за MaxReplays запусков, переносятся в dead-letter.
+func (q *Queue) Replay(ctx context.Context) error {
+	if q.outbox == nil {
+		return nil
+	}
+
+	logger := slog.With("component", "delivery_queue", "op", "replay")
+
+	entries, err := q.outbox.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed list outbox: %w", err)
+	}
+
+	if len(entries) > 0 {
+		logger.Info("replay outbox", "count", len(entries))
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		entryLogger := logger.With("id", entry.ID, "notifier", entry.Notifier, "service_name", entry.Event.ServiceName)
+
+		notifier, ok := q.notifiers[entry.Notifier]
+		if !ok {
+			entryLogger.Warn("notifier not found, moving to dead letter")
+			entry.LastError = fmt.Sprintf("unknown notifier: %s", entry.Notifier)
+			if err := q.outbox.DeadLetter(ctx, entry); err != nil {
+				return fmt.Errorf("failed dead letter entry %s: %w", entry.ID, err)
+			}
+			continue
+		}
+
+		event := entry.Event
+		sendErr := q.send(ctx, notifier, &event)
+		if sendErr == nil {
+			entryLogger.Info("outbox entry delivered")
+			if err := q.outbox.Remove(ctx, entry.ID); err != nil {
+				return fmt.Errorf("failed remove entry %s: %w", entry.ID, err)
+			}
+			continue
+		}
+
+		entry.Attempts++
+		entry.LastError = sendErr.Error()
+
+		if entry.Attempts >= q.policy.MaxReplays {
+			entryLogger.Error("outbox entry failed too many times, moving to dead letter", "err", sendErr, "replays", entry.Attempts)
+			if err := q.outbox.DeadLetter(ctx, entry); err != nil {
+				return fmt.Errorf("failed dead letter entry %s: %w", entry.ID, err)
+			}
+			continue
+		}
+
+		entryLogger.Warn("outbox entry not delivered", "err", sendErr, "replays", entry.Attempts)
+		if err := q.outbox.Put(context.WithoutCancel(ctx), entry); err != nil {
+			return fmt.Errorf("failed update entry %s: %w", entry.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) send(ctx context.Context, notifier domain.Notifier, event *domain.AlertEvent) error {
+	var err error
+	for attempt := 1; attempt <= q.policy.MaxAttempts; attempt++ {
+		err = notifier.Notify(ctx, event)
+		if err == nil {
+			return nil
+		}
+
+		if attempt == q.policy.MaxAttempts {
+			break
+		}
+
+		backoff := q.policy.Backoff(attempt)
+		slog.Warn("notify failed, retrying", "component", "delivery_queue", "service_name", event.ServiceName, "attempt", attempt, "backoff", backoff, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w; retry interrupted: %w", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", q.policy.MaxAttempts, err)
+}
+
+func newEntryID() string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(buf))
+}
@@ -0,0 +1,183 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, alert *domain.AlertEvent) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.calls++
+	if n.calls <= n.failures {
+		return errors.New("smtp is down")
+	}
+
+	return nil
+}
+
+type memoryOutbox struct {
+	mu      sync.Mutex
+	entries map[string]domain.OutboxEntry
+	dead    []domain.OutboxEntry
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{entries: make(map[string]domain.OutboxEntry)}
+}
+
+func (o *memoryOutbox) Put(ctx context.Context, entry domain.OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries[entry.ID] = entry
+	return nil
+}
+
+func (o *memoryOutbox) List(ctx context.Context) ([]domain.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var result []domain.OutboxEntry
+	for _, entry := range o.entries {
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (o *memoryOutbox) Remove(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.entries, id)
+	return nil
+}
+
+func (o *memoryOutbox) DeadLetter(ctx context.Context, entry domain.OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.dead = append(o.dead, entry)
+	delete(o.entries, entry.ID)
+	return nil
+}
+
+var testPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+	MaxReplays:     2,
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+	assert.Equal(t, 5*time.Second, policy.Backoff(30))
+}
+
+func TestQueueDeliver(t *testing.T) {
+	event := &domain.AlertEvent{ServiceName: "example.ru", Status: domain.CRIT}
+
+	t.Run("доставка после повторов", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 2}
+		outbox := newMemoryOutbox()
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, outbox, testPolicy)
+
+		assert.NoError(t, queue.Deliver(t.Context(), "email-0", event))
+		assert.Equal(t, 3, notifier.calls)
+		assert.Empty(t, outbox.entries)
+	})
+
+	t.Run("после исчерпания попыток событие уходит в outbox", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 10}
+		outbox := newMemoryOutbox()
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, outbox, testPolicy)
+
+		err := queue.Deliver(t.Context(), "email-0", event)
+		assert.ErrorContains(t, err, "smtp is down")
+		assert.Equal(t, 3, notifier.calls)
+
+		entries, _ := outbox.List(t.Context())
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "email-0", entries[0].Notifier)
+		assert.Equal(t, "example.ru", entries[0].Event.ServiceName)
+		assert.Contains(t, entries[0].LastError, "smtp is down")
+	})
+
+	t.Run("неизвестный получатель", func(t *testing.T) {
+		queue := NewQueue(nil, nil, testPolicy)
+
+		assert.ErrorContains(t, queue.Deliver(t.Context(), "email-0", event), "unknown notifier")
+	})
+
+	t.Run("без outbox ошибка просто возвращается", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 10}
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, nil, testPolicy)
+
+		assert.Error(t, queue.Deliver(t.Context(), "email-0", event))
+	})
+}
+
+func TestQueueReplay(t *testing.T) {
+	entry := domain.OutboxEntry{
+		ID:       "1",
+		Notifier: "email-0",
+		Event:    domain.AlertEvent{ServiceName: "example.ru", Status: domain.CRIT},
+	}
+
+	t.Run("доставленное событие удаляется", func(t *testing.T) {
+		notifier := &fakeNotifier{}
+		outbox := newMemoryOutbox()
+		outbox.Put(t.Context(), entry)
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, outbox, testPolicy)
+
+		assert.NoError(t, queue.Replay(t.Context()))
+		assert.Equal(t, 1, notifier.calls)
+		assert.Empty(t, outbox.entries)
+		assert.Empty(t, outbox.dead)
+	})
+
+	t.Run("недоставленное остаётся с увеличенным счётчиком, затем уходит в dead-letter", func(t *testing.T) {
+		notifier := &fakeNotifier{failures: 100}
+		outbox := newMemoryOutbox()
+		outbox.Put(t.Context(), entry)
+		queue := NewQueue([]domain.RoutedNotifier{{Name: "email-0", Notifier: notifier}}, outbox, testPolicy)
+
+		assert.NoError(t, queue.Replay(t.Context()))
+		assert.Equal(t, 1, outbox.entries["1"].Attempts)
+		assert.Empty(t, outbox.dead)
+
+		assert.NoError(t, queue.Replay(t.Context()))
+		assert.Empty(t, outbox.entries)
+		assert.Len(t, outbox.dead, 1)
+		assert.Equal(t, 2, outbox.dead[0].Attempts)
+	})
+
+	t.Run("событие для удалённого из конфига получателя уходит в dead-letter", func(t *testing.T) {
+		outbox := newMemoryOutbox()
+		outbox.Put(t.Context(), entry)
+		queue := NewQueue(nil, outbox, testPolicy)
+
+		assert.NoError(t, queue.Replay(t.Context()))
+		assert.Empty(t, outbox.entries)
+		assert.Len(t, outbox.dead, 1)
+		assert.Contains(t, outbox.dead[0].LastError, "unknown notifier")
+	})
+}
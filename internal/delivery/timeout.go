@@ -0,0 +1,32 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+// WithTimeout ограничивает время каждого вызова Notify.
+func WithTimeout(notifier domain.Notifier, timeout time.Duration) domain.Notifier {
+	if timeout <= 0 {
+		return notifier
+	}
+
+	return &timeoutNotifier{
+		notifier: notifier,
+		timeout:  timeout,
+	}
+}
+
+type timeoutNotifier struct {
+	notifier domain.Notifier
+	timeout  time.Duration
+}
+
+func (n *timeoutNotifier) Notify(ctx context.Context, alert *domain.AlertEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	return n.notifier.Notify(ctx, alert)
+}
@@ -3,12 +3,16 @@ package domain
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"mime"
 	"net/http"
-	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,10 +29,29 @@ const (
 	CRIT Severity = 2
 )
 
+func (s Severity) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case WARN:
+		return "warn"
+	case CRIT:
+		return "crit"
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
 type CheckInput struct {
 	Response *http.Response
 	Latency  time.Duration
 	Body     []byte
+
+	// TLS соединение с сервером, для http-проверок совпадает с Response.TLS
+	TLS *tls.ConnectionState
+
+	// DNS записи из ответа резолвера для сервиса типа dns
+	DNS []DNSRecord
 }
 
 type CheckResult struct {
@@ -41,34 +64,60 @@ type CheckRule interface {
 	Check(ctx context.Context, input *CheckInput) CheckResult
 }
 
-func NewStatusCodeRule(expected int) CheckRule {
+// NewStatusCodeRule проверяет, что код ответа входит в один из диапазонов expected.
+// classSeverity задаёт уровень для неожиданного кода по классу (4 для 4xx), по умолчанию CRIT.
+func NewStatusCodeRule(expected []config.StatusCodeRange, classSeverity map[int]Severity) CheckRule {
 	return &StatusCodeRule{
-		expected: expected,
+		expected:      expected,
+		classSeverity: classSeverity,
 	}
 }
 
 type StatusCodeRule struct {
-	expected int
+	expected      []config.StatusCodeRange
+	classSeverity map[int]Severity
 }
 
 func (c *StatusCodeRule) Check(ctx context.Context, input *CheckInput) CheckResult {
 	component := config.TYPE_STATUS_CODE
 	logger := slog.With("component", component)
 
-	if input.Response.StatusCode == c.expected {
-		return CheckResult{
-			RuleType: component,
-			OK:       OK,
+	code := input.Response.StatusCode
+	for _, codeRange := range c.expected {
+		if code >= codeRange.From && code <= codeRange.To {
+			return CheckResult{
+				RuleType: component,
+				OK:       OK,
+			}
 		}
 	}
 
-	logger.Debug("registered error", "expected", c.expected, "actual", input.Response.StatusCode)
+	logger.Debug("registered error", "expected", c.expected, "actual", code)
+
+	severity, ok := c.classSeverity[code/100]
+	if !ok {
+		severity = CRIT
+	}
+
+	expected := make([]string, 0, len(c.expected))
+	for _, codeRange := range c.expected {
+		expected = append(expected, codeRange.String())
+	}
 
 	return CheckResult{
 		RuleType: component,
-		OK:       CRIT,
-		Message:  fmt.Sprintf("ожидается статус %d, получен %d", c.expected, input.Response.StatusCode),
+		OK:       severity,
+		Message:  fmt.Sprintf("ожидается статус %s, получен %d", joinAlternatives(expected), code),
+	}
+}
+
+// joinAlternatives перечисляет варианты через запятую, последний через «или».
+func joinAlternatives(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
 	}
+
+	return strings.Join(items[:len(items)-1], ", ") + " или " + items[len(items)-1]
 }
 
 func NewLatencyRule(maxLatencyMs int) CheckRule {
@@ -101,39 +150,192 @@ func (c *LatencyRule) Check(ctx context.Context, input *CheckInput) CheckResult
 	}
 }
 
-func NewBodyMatchRule(substring string) CheckRule {
+// NewBodyMatchRule проверяет наличие строк в теле: всех, или хотя бы одной при matchAny.
+func NewBodyMatchRule(substrings []string, matchAny bool) CheckRule {
 	return &BodyMatchRule{
-		substring: substring,
+		substrings: substrings,
+		matchAny:   matchAny,
 	}
 }
 
 type BodyMatchRule struct {
-	substring string
+	substrings []string
+	matchAny   bool
 }
 
 func (c *BodyMatchRule) Check(ctx context.Context, input *CheckInput) CheckResult {
 	component := config.TYPE_BODY_CONTAINS
 	logger := slog.With("component", component)
 
-	bodyStr := bodyAsUTF8(input)
-	normBody := normalizeSpace(bodyStr)
-	normSub := normalizeSpace(c.substring)
-	if strings.Contains(normBody, normSub) {
+	found, missing := splitBySubstrings(bodyAsUTF8(input), c.substrings)
+
+	if len(missing) == 0 || (c.matchAny && len(found) > 0) {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
+
+	logger.Debug("registered error", "missing", missing)
+
+	var message string
+	switch {
+	case c.matchAny && len(missing) > 1:
+		message = fmt.Sprintf("не найдена ни одна из строк - %s", strings.Join(missing, ", "))
+	case len(missing) > 1:
+		message = fmt.Sprintf("отсутствуют строки - %s", strings.Join(missing, ", "))
+	default:
+		message = fmt.Sprintf("отсутствует строка - %s", missing[0])
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  message,
+	}
+}
+
+func NewBodyNotContainsRule(substrings []string) CheckRule {
+	return &BodyNotContainsRule{
+		substrings: substrings,
+	}
+}
+
+// BodyNotContainsRule CRIT, если в теле найдена хотя бы одна из строк, например текст страницы ошибки.
+type BodyNotContainsRule struct {
+	substrings []string
+}
+
+func (c *BodyNotContainsRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_BODY_NOT_CONTAINS
+	logger := slog.With("component", component)
+
+	found, _ := splitBySubstrings(bodyAsUTF8(input), c.substrings)
+	if len(found) == 0 {
 		return CheckResult{
 			RuleType: component,
 			OK:       OK,
 		}
 	}
 
-	logger.Debug("registered error", "substring", c.substring)
+	logger.Debug("registered error", "found", found)
+
+	message := fmt.Sprintf("найдена строка - %s", found[0])
+	if len(found) > 1 {
+		message = fmt.Sprintf("найдены строки - %s", strings.Join(found, ", "))
+	}
 
 	return CheckResult{
 		RuleType: component,
 		OK:       CRIT,
-		Message:  fmt.Sprintf("отсутствует строка - %s", c.substring),
+		Message:  message,
 	}
 }
 
+// splitBySubstrings делит строки на найденные в теле и отсутствующие, сравнение после normalizeSpace.
+func splitBySubstrings(body string, substrings []string) (found []string, missing []string) {
+	normBody := normalizeSpace(body)
+	for _, substring := range substrings {
+		if strings.Contains(normBody, normalizeSpace(substring)) {
+			found = append(found, substring)
+		} else {
+			missing = append(missing, substring)
+		}
+	}
+
+	return found, missing
+}
+
+// NewBodyRegexRule pattern должен быть проверен при загрузке конфига.
+func NewBodyRegexRule(pattern string) CheckRule {
+	return &BodyRegexRule{
+		re: regexp.MustCompile(pattern),
+	}
+}
+
+// BodyRegexRule CRIT, если тело не совпадает с выражением. Значения именованных групп попадают в сообщение.
+type BodyRegexRule struct {
+	re *regexp.Regexp
+}
+
+func (c *BodyRegexRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_BODY_REGEX
+	logger := slog.With("component", component)
+
+	match := c.re.FindStringSubmatch(bodyAsUTF8(input))
+	if match == nil {
+		logger.Debug("registered error", "pattern", c.re.String())
+
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("нет совпадения с выражением - %s", c.re),
+		}
+	}
+
+	var groups []string
+	for idx, name := range c.re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+
+		groups = append(groups, fmt.Sprintf("%s=%s", name, match[idx]))
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       OK,
+		Message:  strings.Join(groups, ", "),
+	}
+}
+
+// NewBodyNotRegexRule pattern должен быть проверен при загрузке конфига.
+func NewBodyNotRegexRule(pattern string) CheckRule {
+	return &BodyNotRegexRule{
+		re: regexp.MustCompile(pattern),
+	}
+}
+
+// BodyNotRegexRule CRIT, если в теле есть совпадение с выражением.
+type BodyNotRegexRule struct {
+	re *regexp.Regexp
+}
+
+func (c *BodyNotRegexRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_BODY_NOT_REGEX
+	logger := slog.With("component", component)
+
+	body := bodyAsUTF8(input)
+	loc := c.re.FindStringIndex(body)
+	if loc == nil {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
+	match := body[loc[0]:loc[1]]
+
+	logger.Debug("registered error", "pattern", c.re.String(), "match", match)
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  fmt.Sprintf("найдено совпадение с выражением %s - %s", c.re, truncate(match, bodyMatchMaxLen)),
+	}
+}
+
+const bodyMatchMaxLen = 100
+
+// truncate обрезает строку до limit символов, чтобы в уведомление не попала половина страницы.
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+
+	return string(runes[:limit]) + "…"
+}
+
 // bodyAsUTF8 декодирует тело ответа в UTF-8. Сначала пробует определить кодировку по содержимому
 // (часто сервер отдаёт charset=utf-8 в заголовке, а тело в windows-1251).
 func bodyAsUTF8(input *CheckInput) string {
@@ -176,25 +378,55 @@ func normalizeSpace(s string) string {
 	return b.String()
 }
 
-func NewHeaderRule(name string, value string) CheckRule {
-	return &HeaderRule{
-		name:  name,
-		value: value,
+// NewHeaderRule mode — один из config.HEADER_MODE_*, пустой означает equals. Для equals, contains и regex
+// проверяются все значения заголовка: с matchAny достаточно одного подходящего, иначе должны подойти все.
+func NewHeaderRule(name string, value string, mode string, matchAny bool) CheckRule {
+	rule := &HeaderRule{
+		name:     name,
+		value:    value,
+		mode:     mode,
+		matchAny: matchAny,
+	}
+
+	if mode == config.HEADER_MODE_REGEX {
+		rule.re = regexp.MustCompile(value)
 	}
+
+	return rule
 }
 
 type HeaderRule struct {
-	name  string
-	value string
+	name     string
+	value    string
+	mode     string
+	matchAny bool
+	re       *regexp.Regexp
 }
 
 func (c *HeaderRule) Check(ctx context.Context, input *CheckInput) CheckResult {
 	component := config.TYPE_HEADER
 	logger := slog.With("component", component)
 
-	value := input.Response.Header.Get(c.name)
+	values, found := headerValues(input.Response.Header, c.name)
+
+	if c.mode == config.HEADER_MODE_ABSENT {
+		if found {
+			logger.Debug("registered error, header must be absent", "header", c.name, "actual_value", values)
+
+			return CheckResult{
+				RuleType: component,
+				OK:       CRIT,
+				Message:  fmt.Sprintf("заголовок '%s' присутствует со значением '%s'", c.name, strings.Join(values, "; ")),
+			}
+		}
+
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
 
-	if value == "" {
+	if !found {
 		logger.Debug("registered error, header not found", "header", c.name)
 
 		return CheckResult{
@@ -204,32 +436,108 @@ func (c *HeaderRule) Check(ctx context.Context, input *CheckInput) CheckResult {
 		}
 	}
 
-	if value != c.value {
-		logger.Debug("registered error, value not equal", "header", c.name, "expected_value", c.value, "actual_value", value)
+	if c.mode == config.HEADER_MODE_PRESENT {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
 
+	var mismatched []string
+	for _, value := range values {
+		if !c.matchValue(value) {
+			mismatched = append(mismatched, value)
+		}
+	}
+
+	if len(mismatched) == 0 || (c.matchAny && len(mismatched) < len(values)) {
 		return CheckResult{
 			RuleType: component,
-			OK:       CRIT,
-			Message:  fmt.Sprintf("значение '%s' заголовока '%s' не соответствует значению '%s'", value, c.name, c.value),
+			OK:       OK,
 		}
 	}
 
+	logger.Debug("registered error, value not match", "header", c.name, "mode", c.mode, "expected_value", c.value, "actual_value", values)
+
+	value := mismatched[0]
+	if c.matchAny {
+		value = strings.Join(mismatched, "; ")
+	}
+
+	var message string
+	switch c.mode {
+	case config.HEADER_MODE_CONTAINS:
+		message = fmt.Sprintf("значение '%s' заголовка '%s' не содержит '%s'", value, c.name, c.value)
+	case config.HEADER_MODE_REGEX:
+		message = fmt.Sprintf("значение '%s' заголовка '%s' не совпадает с выражением %s", value, c.name, c.value)
+	default:
+		message = fmt.Sprintf("значение '%s' заголовока '%s' не соответствует значению '%s'", value, c.name, c.value)
+	}
+
 	return CheckResult{
 		RuleType: component,
-		OK:       OK,
+		OK:       CRIT,
+		Message:  message,
 	}
 }
 
-func NewJSONFieldRule(path string, expected any) CheckRule {
-	return &JSONFieldRule{
-		path:     path,
-		expected: expected,
+func (c *HeaderRule) matchValue(value string) bool {
+	switch c.mode {
+	case config.HEADER_MODE_CONTAINS:
+		return strings.Contains(value, c.value)
+	case config.HEADER_MODE_REGEX:
+		return c.re.MatchString(value)
+	default:
+		return value == c.value
 	}
 }
 
+// headerValues собирает значения всех ключей, совпадающих с name без учёта регистра:
+// кроме канонических ключей в http.Header могут оказаться записанные как есть.
+func headerValues(header http.Header, name string) ([]string, bool) {
+	var (
+		values []string
+		found  bool
+	)
+
+	// ключи сортируются, чтобы порядок значений в сообщениях не зависел от обхода map
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		if strings.EqualFold(key, name) {
+			found = true
+			values = append(values, header[key]...)
+		}
+	}
+
+	return values, found
+}
+
+// NewJSONFieldRule проверяет значение по пути path: при невыполнении условия с expected — CRIT,
+// с warnExpected — WARN. warnExpected может быть nil, пустой operator означает eq.
+// contentType — требуемый тип ответа, пустой означает application/json или *+json, config.JSON_CONTENT_TYPE_ANY — без проверки.
+func NewJSONFieldRule(path string, operator string, expected any, warnExpected any, contentType string) CheckRule {
+	rule := &JSONFieldRule{
+		path:        path,
+		operator:    operator,
+		contentType: strings.ToLower(contentType),
+	}
+
+	if operator != config.JSON_OP_EXISTS && operator != config.JSON_OP_NOT_EXISTS {
+		rule.crit = newJSONCondition(operator, expected)
+		if warnExpected != nil {
+			rule.warn = newJSONCondition(operator, warnExpected)
+		}
+	}
+
+	return rule
+}
+
 type JSONFieldRule struct {
-	path     string
-	expected any
+	path        string
+	operator    string
+	contentType string
+
+	crit *jsonCondition
+	warn *jsonCondition
 }
 
 func (c *JSONFieldRule) Check(ctx context.Context, input *CheckInput) CheckResult {
@@ -245,16 +553,39 @@ func (c *JSONFieldRule) Check(ctx context.Context, input *CheckInput) CheckResul
 		}
 	}
 
-	if input.Response.Header.Get("Content-Type") != "application/json" {
-		logger.Debug("response type in header not valid", "content-type", input.Response.Header.Get("Content-Type"))
+	if header := input.Response.Header.Get("Content-Type"); !c.acceptContentType(header) {
+		logger.Debug("response type in header not valid", "content-type", header, "expected", c.contentType)
+
+		message := fmt.Sprintf("некорректный заголовок ответа для ответа json - %s", header)
+		if c.contentType != "" {
+			message = fmt.Sprintf("ожидается заголовок ответа %s, получен %s", c.contentType, header)
+		}
+
 		return CheckResult{
 			RuleType: component,
 			OK:       CRIT,
-			Message:  fmt.Sprintf("некорректный заголовок ответа для ответа json - %s", input.Response.Header.Get("Content-Type")),
+			Message:  message,
 		}
 	}
 
 	result := gjson.Get(string(input.Body), c.path)
+
+	if c.operator == config.JSON_OP_NOT_EXISTS {
+		if result.Exists() {
+			logger.Debug("the path exists", "path", c.path)
+			return CheckResult{
+				RuleType: component,
+				OK:       CRIT,
+				Message:  fmt.Sprintf("путь '%s' присутствует в ответе сервера со значением %v", c.path, result.Value()),
+			}
+		}
+
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
+
 	if !result.Exists() {
 		logger.Debug("the path not found", "path", c.path)
 		return CheckResult{
@@ -264,12 +595,49 @@ func (c *JSONFieldRule) Check(ctx context.Context, input *CheckInput) CheckResul
 		}
 	}
 
-	if !reflect.DeepEqual(result.Value(), c.expected) {
-		logger.Debug("value under path not valid", "path", c.path, "expected", c.expected, "actual", result.Value())
+	if c.operator == config.JSON_OP_EXISTS {
 		return CheckResult{
 			RuleType: component,
-			OK:       CRIT,
-			Message:  fmt.Sprintf("'%s' значение %v не соответсвует ожидаемому %v", c.path, result.Value(), c.expected),
+			OK:       OK,
+		}
+	}
+
+	for _, level := range []struct {
+		condition *jsonCondition
+		severity  Severity
+	}{
+		{c.crit, CRIT},
+		{c.warn, WARN},
+	} {
+		if level.condition == nil {
+			continue
+		}
+
+		matched, err := level.condition.match(result)
+		if err != nil {
+			logger.Debug("value under path not comparable", "path", c.path, "operator", c.operator, "err", err)
+			return CheckResult{
+				RuleType: component,
+				OK:       CRIT,
+				Message:  fmt.Sprintf("'%s' %s", c.path, err),
+			}
+		}
+
+		if matched {
+			continue
+		}
+
+		logger.Debug("value under path not valid", "path", c.path, "operator", c.operator, "expected", level.condition.operand, "actual", result.Value())
+
+		message := fmt.Sprintf("'%s' значение %v не удовлетворяет условию %s", c.path, result.Value(), level.condition)
+		if c.operator == config.JSON_OP_EQ || c.operator == "" {
+			message = fmt.Sprintf("'%s' значение %v не соответсвует ожидаемому %v", c.path, result.Value(), level.condition.operand)
+		}
+
+		return CheckResult{
+			RuleType: component,
+			OK:       level.severity,
+			Message:  message,
 		}
 	}
 
@@ -279,6 +647,25 @@ func (c *JSONFieldRule) Check(ctx context.Context, input *CheckInput) CheckResul
 	}
 }
 
+// acceptContentType сравнивает только тип без параметров, т.е. charset и прочее не учитываются.
+func (c *JSONFieldRule) acceptContentType(header string) bool {
+	if c.contentType == config.JSON_CONTENT_TYPE_ANY {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+
+	if c.contentType != "" {
+		wanted, _, _ := mime.ParseMediaType(c.contentType)
+		return mediaType == wanted
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
 func NewSSLChecker(warnDays int, critDays int) CheckRule {
 	return &SSLChecker{
 		warnDays: warnDays,
@@ -295,7 +682,8 @@ func (c *SSLChecker) Check(ctx context.Context, input *CheckInput) CheckResult {
 	component := config.TYPE_SSL_NOT_EXPIRED
 	logger := slog.With("component", component)
 
-	if input.Response.TLS == nil {
+	state := tlsState(input)
+	if state == nil {
 		logger.Debug("tls info not found in server response")
 		return CheckResult{
 			RuleType: component,
@@ -304,7 +692,7 @@ func (c *SSLChecker) Check(ctx context.Context, input *CheckInput) CheckResult {
 		}
 	}
 
-	if len(input.Response.TLS.PeerCertificates) == 0 {
+	if len(state.PeerCertificates) == 0 {
 		logger.Warn("any certificates not found in server response")
 		return CheckResult{
 			RuleType: component,
@@ -313,7 +701,7 @@ func (c *SSLChecker) Check(ctx context.Context, input *CheckInput) CheckResult {
 		}
 	}
 
-	cert := input.Response.TLS.PeerCertificates[0]
+	cert := state.PeerCertificates[0]
 	untilDays := int(time.Until(cert.NotAfter).Hours()) / 24
 	if untilDays < c.critDays {
 		logger.Debug("certificate expire very soon")
@@ -338,3 +726,16 @@ func (c *SSLChecker) Check(ctx context.Context, input *CheckInput) CheckResult {
 		OK:       OK,
 	}
 }
+
+// tlsState состояние TLS-соединения: у сервисов типа tls ответа нет, у http оно есть и в Response.
+func tlsState(input *CheckInput) *tls.ConnectionState {
+	if input.TLS != nil {
+		return input.TLS
+	}
+
+	if input.Response != nil {
+		return input.Response.TLS
+	}
+
+	return nil
+}
@@ -14,7 +14,7 @@ import (
 
 func TestStatusCodeRule(t *testing.T) {
 	t.Run("успешный тест", func(t *testing.T) {
-		rule := StatusCodeRule{expected: 200}
+		rule := StatusCodeRule{expected: []config.StatusCodeRange{{From: 200, To: 200}}}
 		input := &CheckInput{
 			Response: &http.Response{StatusCode: 200},
 		}
@@ -24,7 +24,7 @@ func TestStatusCodeRule(t *testing.T) {
 	})
 
 	t.Run("неуспешный тест", func(t *testing.T) {
-		rule := StatusCodeRule{expected: 200}
+		rule := StatusCodeRule{expected: []config.StatusCodeRange{{From: 200, To: 200}}}
 		input := &CheckInput{
 			Response: &http.Response{StatusCode: 500},
 		}
@@ -33,6 +33,28 @@ func TestStatusCodeRule(t *testing.T) {
 		assert.Equal(t, Severity(CRIT), got.OK)
 		assert.Equal(t, "ожидается статус 200, получен 500", got.Message)
 	})
+
+	expected := []config.StatusCodeRange{{From: 200, To: 200}, {From: 204, To: 204}, {From: 300, To: 399}}
+	classSeverity := map[int]Severity{4: WARN}
+
+	t.Run("код из набора и класса", func(t *testing.T) {
+		rule := NewStatusCodeRule(expected, classSeverity)
+		for _, code := range []int{200, 204, 301} {
+			got := rule.Check(t.Context(), &CheckInput{Response: &http.Response{StatusCode: code}})
+			assert.Equal(t, OK, got.OK, code)
+		}
+	})
+
+	t.Run("уровень по классу кода", func(t *testing.T) {
+		rule := NewStatusCodeRule(expected, classSeverity)
+
+		got := rule.Check(t.Context(), &CheckInput{Response: &http.Response{StatusCode: 404}})
+		assert.Equal(t, WARN, got.OK)
+		assert.Equal(t, "ожидается статус 200, 204 или 3xx, получен 404", got.Message)
+
+		got = rule.Check(t.Context(), &CheckInput{Response: &http.Response{StatusCode: 502}})
+		assert.Equal(t, CRIT, got.OK)
+	})
 }
 
 func TestLatencyRule(t *testing.T) {
@@ -56,7 +78,7 @@ func TestLatencyRule(t *testing.T) {
 
 func TestBodyMatchRule(t *testing.T) {
 	t.Run("успешный тест", func(t *testing.T) {
-		rule := BodyMatchRule{substring: "ok"}
+		rule := BodyMatchRule{substrings: []string{"ok"}}
 		input := &CheckInput{Body: []byte("ok")}
 		got := rule.Check(t.Context(), input)
 		assert.Equal(t, config.TYPE_BODY_CONTAINS, got.RuleType)
@@ -64,13 +86,100 @@ func TestBodyMatchRule(t *testing.T) {
 	})
 
 	t.Run("неуспешный тест", func(t *testing.T) {
-		rule := BodyMatchRule{substring: "ok"}
+		rule := BodyMatchRule{substrings: []string{"ok"}}
 		input := &CheckInput{Body: []byte("fail")}
 		got := rule.Check(t.Context(), input)
 		assert.Equal(t, config.TYPE_BODY_CONTAINS, got.RuleType)
 		assert.Equal(t, Severity(CRIT), got.OK)
 		assert.Equal(t, "отсутствует строка - ok", got.Message)
 	})
+
+	t.Run("all — перечисляет отсутствующие строки", func(t *testing.T) {
+		rule := NewBodyMatchRule([]string{"header", "catalog", "footer", "cart"}, false)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("header catalog")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "отсутствуют строки - footer, cart", got.Message)
+	})
+
+	t.Run("any — достаточно одной строки", func(t *testing.T) {
+		rule := NewBodyMatchRule([]string{"Войти", "Выйти"}, true)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("<a>Выйти</a>")})
+		assert.Equal(t, OK, got.OK)
+	})
+
+	t.Run("any — ни одной строки", func(t *testing.T) {
+		rule := NewBodyMatchRule([]string{"Войти", "Выйти"}, true)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("503")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "не найдена ни одна из строк - Войти, Выйти", got.Message)
+	})
+}
+
+func TestBodyNotContainsRule(t *testing.T) {
+	t.Run("успешный тест", func(t *testing.T) {
+		rule := NewBodyNotContainsRule([]string{"Fatal error"})
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("<html>ok</html>")})
+		assert.Equal(t, config.TYPE_BODY_NOT_CONTAINS, got.RuleType)
+		assert.Equal(t, OK, got.OK)
+	})
+
+	t.Run("строка найдена", func(t *testing.T) {
+		rule := NewBodyNotContainsRule([]string{"Fatal error", "Warning: mysqli"})
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("<b>Warning:\u00a0mysqli_connect()</b>")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "найдена строка - Warning: mysqli", got.Message)
+	})
+
+	t.Run("найдено несколько строк", func(t *testing.T) {
+		rule := NewBodyNotContainsRule([]string{"Fatal error", "Warning: mysqli"})
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("Warning: mysqli_connect() Fatal error")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "найдены строки - Fatal error, Warning: mysqli", got.Message)
+	})
+}
+
+func TestBodyRegexRule(t *testing.T) {
+	t.Run("именованные группы в сообщении", func(t *testing.T) {
+		rule := NewBodyRegexRule(`version: (?P<version>[\d.]+), env: (?P<env>\w+)`)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("version: 1.2.3, env: prod")})
+		assert.Equal(t, config.TYPE_BODY_REGEX, got.RuleType)
+		assert.Equal(t, OK, got.OK)
+		assert.Equal(t, "version=1.2.3, env=prod", got.Message)
+	})
+
+	t.Run("нет совпадения", func(t *testing.T) {
+		rule := NewBodyRegexRule(`status: ok`)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("status: fail")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "нет совпадения с выражением - status: ok", got.Message)
+	})
+
+	t.Run("тело в windows-1251", func(t *testing.T) {
+		// «Привет» в windows-1251
+		body := append([]byte(`<html><head><meta charset="windows-1251"></head><body>`), 0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2)
+		rule := NewBodyRegexRule(`<body>Привет`)
+		got := rule.Check(t.Context(), &CheckInput{
+			Response: &http.Response{Header: http.Header{"Content-Type": {"text/html; charset=utf-8"}}},
+			Body:     body,
+		})
+		assert.Equal(t, OK, got.OK)
+	})
+}
+
+func TestBodyNotRegexRule(t *testing.T) {
+	t.Run("успешный тест", func(t *testing.T) {
+		rule := NewBodyNotRegexRule(`(?i)fatal error`)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("ok")})
+		assert.Equal(t, config.TYPE_BODY_NOT_REGEX, got.RuleType)
+		assert.Equal(t, OK, got.OK)
+	})
+
+	t.Run("совпадение найдено", func(t *testing.T) {
+		rule := NewBodyNotRegexRule(`(?i)fatal error: [^<]+`)
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("<b>Fatal error: Allowed memory size exhausted</b>")})
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "найдено совпадение с выражением (?i)fatal error: [^<]+ - Fatal error: Allowed memory size exhausted", got.Message)
+	})
 }
 
 func TestHeaderRule(t *testing.T) {
@@ -111,16 +220,69 @@ func TestHeaderRule(t *testing.T) {
 		assert.Equal(t, Severity(CRIT), got.OK)
 		assert.Equal(t, "заголовок 'X-Auth' отсутствует", got.Message)
 	})
+
+	response := &CheckInput{
+		Response: &http.Response{
+			Header: http.Header{
+				"Cache-Control": []string{"public, max-age=3600"},
+				"Set-Cookie":    []string{"session=1; Secure; HttpOnly", "lang=ru"},
+				"x-powered-by":  []string{"PHP/8.1"},
+			},
+		},
+	}
+
+	t.Run("contains, имя без учёта регистра", func(t *testing.T) {
+		got := NewHeaderRule("cache-control", "max-age", config.HEADER_MODE_CONTAINS, false).Check(t.Context(), response)
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("все значения должны подойти", func(t *testing.T) {
+		got := NewHeaderRule("Set-Cookie", "Secure", config.HEADER_MODE_CONTAINS, false).Check(t.Context(), response)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "значение 'lang=ru' заголовка 'Set-Cookie' не содержит 'Secure'", got.Message)
+	})
+
+	t.Run("достаточно одного значения", func(t *testing.T) {
+		got := NewHeaderRule("Set-Cookie", "^session=", config.HEADER_MODE_REGEX, true).Check(t.Context(), response)
+		assert.Equal(t, Severity(OK), got.OK)
+
+		got = NewHeaderRule("Set-Cookie", "^token=", config.HEADER_MODE_REGEX, true).Check(t.Context(), response)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "значение 'session=1; Secure; HttpOnly; lang=ru' заголовка 'Set-Cookie' не совпадает с выражением ^token=", got.Message)
+	})
+
+	t.Run("значения из ключей разного регистра идут в стабильном порядке", func(t *testing.T) {
+		response := &CheckInput{
+			Response: &http.Response{
+				Header: http.Header{
+					"X-Trace": []string{"b"},
+					"x-trace": []string{"c"},
+					"X-TRACE": []string{"a"},
+				},
+			},
+		}
+
+		for range 10 {
+			got := NewHeaderRule("X-Trace", "z", config.HEADER_MODE_CONTAINS, true).Check(t.Context(), response)
+			assert.Equal(t, "значение 'a; b; c' заголовка 'X-Trace' не содержит 'z'", got.Message)
+		}
+	})
+
+	t.Run("present и absent", func(t *testing.T) {
+		assert.Equal(t, Severity(OK), NewHeaderRule("Set-Cookie", "", config.HEADER_MODE_PRESENT, false).Check(t.Context(), response).OK)
+		assert.Equal(t, Severity(OK), NewHeaderRule("Server", "", config.HEADER_MODE_ABSENT, false).Check(t.Context(), response).OK)
+
+		got := NewHeaderRule("X-Powered-By", "", config.HEADER_MODE_ABSENT, false).Check(t.Context(), response)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "заголовок 'X-Powered-By' присутствует со значением 'PHP/8.1'", got.Message)
+	})
 }
 
 func TestJSONFieldRule(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("успешный тест", func(t *testing.T) {
-		rule := JSONFieldRule{
-			path:     "status",
-			expected: "ok",
-		}
+		rule := NewJSONFieldRule("status", "", "ok", nil, "")
 		input := &CheckInput{
 			Response: &http.Response{
 				Header: http.Header{"Content-Type": []string{"application/json"}},
@@ -133,7 +295,7 @@ func TestJSONFieldRule(t *testing.T) {
 	})
 
 	t.Run("невалидный JSON", func(t *testing.T) {
-		rule := JSONFieldRule{path: "x", expected: nil}
+		rule := NewJSONFieldRule("x", "", nil, nil, "")
 		input := &CheckInput{
 			Response: &http.Response{Header: http.Header{"Content-Type": []string{"application/json"}}},
 			Body:     []byte(`{invalid`),
@@ -145,7 +307,7 @@ func TestJSONFieldRule(t *testing.T) {
 	})
 
 	t.Run("некорректный Content-Type", func(t *testing.T) {
-		rule := JSONFieldRule{path: "x", expected: nil}
+		rule := NewJSONFieldRule("x", "", nil, nil, "")
 		input := &CheckInput{
 			Response: &http.Response{Header: http.Header{"Content-Type": []string{"text/plain"}}},
 			Body:     []byte(`{}`),
@@ -157,10 +319,7 @@ func TestJSONFieldRule(t *testing.T) {
 	})
 
 	t.Run("путь отсутствует", func(t *testing.T) {
-		rule := JSONFieldRule{
-			path:     "missing.path",
-			expected: nil,
-		}
+		rule := NewJSONFieldRule("missing.path", "", nil, nil, "")
 		input := &CheckInput{
 			Response: &http.Response{Header: http.Header{"Content-Type": []string{"application/json"}}},
 			Body:     []byte(`{"a":1}`),
@@ -172,10 +331,7 @@ func TestJSONFieldRule(t *testing.T) {
 	})
 
 	t.Run("значение не соответствует ожидаемому", func(t *testing.T) {
-		rule := JSONFieldRule{
-			path:     "status",
-			expected: "ok",
-		}
+		rule := NewJSONFieldRule("status", "", "ok", nil, "")
 		input := &CheckInput{
 			Response: &http.Response{Header: http.Header{"Content-Type": []string{"application/json"}}},
 			Body:     []byte(`{"status":"fail"}`),
@@ -187,6 +343,90 @@ func TestJSONFieldRule(t *testing.T) {
 	})
 }
 
+func TestJSONFieldRuleOperators(t *testing.T) {
+	body := []byte(`{"status":"degraded","queue_depth":700,"errors":[],"version":"2.4.1","db":{"ok":true}}`)
+	input := &CheckInput{
+		Response: &http.Response{Header: http.Header{"Content-Type": []string{"application/json"}}},
+		Body:     body,
+	}
+
+	testCases := []struct {
+		name         string
+		path         string
+		operator     string
+		expected     any
+		warnExpected any
+		severity     Severity
+		message      string
+	}{
+		{"eq с числом из toml", "queue_depth", config.JSON_OP_EQ, int64(700), nil, OK, ""},
+		{"eq с объектом", "db", config.JSON_OP_EQ, map[string]any{"ok": true}, nil, OK, ""},
+		{"ne", "status", config.JSON_OP_NE, "down", nil, OK, ""},
+		{"lt — порог warn", "queue_depth", config.JSON_OP_LT, int64(1000), int64(500), WARN, "'queue_depth' значение 700 не удовлетворяет условию < 500"},
+		{"lt — порог crit", "queue_depth", config.JSON_OP_LT, int64(600), int64(500), CRIT, "'queue_depth' значение 700 не удовлетворяет условию < 600"},
+		{"lte", "queue_depth", config.JSON_OP_LTE, 700.0, nil, OK, ""},
+		{"gt", "queue_depth", config.JSON_OP_GT, int64(1), nil, OK, ""},
+		{"gte строки", "status", config.JSON_OP_GTE, int64(1), nil, CRIT, "'status' значение degraded не число"},
+		{"in", "status", config.JSON_OP_IN, []any{"ok", "degraded"}, []any{"ok"}, WARN, "'status' значение degraded не удовлетворяет условию in [ok]"},
+		{"regex", "version", config.JSON_OP_REGEX, `^2\.`, nil, OK, ""},
+		{"regex не совпадает", "version", config.JSON_OP_REGEX, `^3\.`, nil, CRIT, "'version' значение 2.4.1 не удовлетворяет условию ~ ^3\\."},
+		{"exists", "db.ok", config.JSON_OP_EXISTS, nil, nil, OK, ""},
+		{"not_exists", "db.ok", config.JSON_OP_NOT_EXISTS, nil, nil, CRIT, "путь 'db.ok' присутствует в ответе сервера со значением true"},
+		{"not_exists — пути нет", "db.error", config.JSON_OP_NOT_EXISTS, nil, nil, OK, ""},
+		{"len_eq — пустой массив", "errors", config.JSON_OP_LEN_EQ, int64(0), nil, OK, ""},
+		{"len_gt строки", "version", config.JSON_OP_LEN_GT, int64(10), nil, CRIT, "'version' значение 2.4.1 не удовлетворяет условию длина > 10"},
+		{"len_eq числа", "queue_depth", config.JSON_OP_LEN_EQ, int64(0), nil, CRIT, "'queue_depth' у значения 700 нет длины"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule := NewJSONFieldRule(testCase.path, testCase.operator, testCase.expected, testCase.warnExpected, "")
+			got := rule.Check(t.Context(), input)
+			assert.Equal(t, testCase.severity, got.OK)
+			assert.Equal(t, testCase.message, got.Message)
+		})
+	}
+}
+
+func TestJSONFieldRuleContentType(t *testing.T) {
+	check := func(contentType string, header string) CheckResult {
+		rule := NewJSONFieldRule("status", "", "ok", nil, contentType)
+		return rule.Check(t.Context(), &CheckInput{
+			Response: &http.Response{Header: http.Header{"Content-Type": []string{header}}},
+			Body:     []byte(`{"status":"ok"}`),
+		})
+	}
+
+	t.Run("json с параметрами и суффиксом +json", func(t *testing.T) {
+		for _, header := range []string{
+			"application/json; charset=utf-8",
+			"Application/JSON",
+			"application/problem+json",
+			"application/vnd.api+json; charset=utf-8",
+		} {
+			assert.Equal(t, OK, check("", header).OK, header)
+		}
+	})
+
+	t.Run("не json", func(t *testing.T) {
+		got := check("", "text/html; charset=utf-8")
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "некорректный заголовок ответа для ответа json - text/html; charset=utf-8", got.Message)
+	})
+
+	t.Run("без проверки заголовка", func(t *testing.T) {
+		assert.Equal(t, OK, check(config.JSON_CONTENT_TYPE_ANY, "text/plain").OK)
+	})
+
+	t.Run("требуется конкретный тип", func(t *testing.T) {
+		assert.Equal(t, OK, check("application/vnd.api+json", "application/vnd.api+json; charset=utf-8").OK)
+
+		got := check("application/vnd.api+json", "application/json")
+		assert.Equal(t, CRIT, got.OK)
+		assert.Equal(t, "ожидается заголовок ответа application/vnd.api+json, получен application/json", got.Message)
+	})
+}
+
 func TestSSLChecker(t *testing.T) {
 	ctx := context.Background()
 
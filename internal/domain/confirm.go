@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// ConfirmPolicy сколько проверок подряд должны показать ухудшение (Failures) или
+// улучшение (Recoveries), прежде чем состояние сервиса изменится.
+type ConfirmPolicy struct {
+	Failures      int
+	Recoveries    int
+	RetryInterval time.Duration
+}
+
+// ConfirmResults сравнивает новые результаты с подтверждённым состоянием сервиса.
+// Если уровень не изменился, результаты принимаются сразу. Иначе изменение копится
+// в state.PendingCount и принимается, когда достигнут порог политики; до этого
+// возвращаются прежние результаты и pending = true.
+func ConfirmResults(policy ConfirmPolicy, state *ServiceStatus, results []CheckResult) (confirmed []CheckResult, pending bool) {
+	actualStatus := GetMaxSeverity(results)
+	confirmedStatus := GetMaxSeverity(state.CheckResults)
+
+	if actualStatus == confirmedStatus {
+		state.PendingCount = 0
+		return results, false
+	}
+
+	worse := actualStatus > confirmedStatus
+
+	// направление изменения сменилось — начинаем отсчёт заново
+	if state.PendingCount > 0 && (state.PendingSeverity > confirmedStatus) != worse {
+		state.PendingCount = 0
+	}
+
+	state.PendingCount++
+	state.PendingSeverity = actualStatus
+
+	threshold := policy.Recoveries
+	if worse {
+		threshold = policy.Failures
+	}
+
+	if state.PendingCount >= threshold {
+		state.PendingCount = 0
+		return results, false
+	}
+
+	return state.CheckResults, true
+}
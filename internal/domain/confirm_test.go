@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmResults(t *testing.T) {
+	policy := ConfirmPolicy{Failures: 3, Recoveries: 2}
+
+	t.Run("без изменения уровня результаты принимаются сразу", func(t *testing.T) {
+		state := &ServiceStatus{CheckResults: resultsWithSeverity(CRIT), PendingCount: 1, PendingSeverity: OK}
+		newResults := []CheckResult{{RuleType: "check", OK: CRIT, Message: "другое сообщение"}}
+
+		got, pending := ConfirmResults(policy, state, newResults)
+		assert.False(t, pending)
+		assert.Equal(t, newResults, got)
+		assert.Equal(t, 0, state.PendingCount)
+	})
+
+	t.Run("падение подтверждается только после confirm_failures проверок подряд", func(t *testing.T) {
+		state := &ServiceStatus{CheckResults: resultsWithSeverity(OK)}
+
+		got, pending := ConfirmResults(policy, state, resultsWithSeverity(CRIT))
+		assert.True(t, pending)
+		assert.Equal(t, resultsWithSeverity(OK), got)
+
+		_, pending = ConfirmResults(policy, state, resultsWithSeverity(WARN))
+		assert.True(t, pending)
+		assert.Equal(t, 2, state.PendingCount)
+
+		got, pending = ConfirmResults(policy, state, resultsWithSeverity(CRIT))
+		assert.False(t, pending)
+		assert.Equal(t, resultsWithSeverity(CRIT), got)
+		assert.Equal(t, 0, state.PendingCount)
+	})
+
+	t.Run("единичный сбой сбрасывается успешной проверкой", func(t *testing.T) {
+		state := &ServiceStatus{CheckResults: resultsWithSeverity(OK)}
+
+		_, pending := ConfirmResults(policy, state, resultsWithSeverity(CRIT))
+		assert.True(t, pending)
+
+		got, pending := ConfirmResults(policy, state, resultsWithSeverity(OK))
+		assert.False(t, pending)
+		assert.Equal(t, resultsWithSeverity(OK), got)
+		assert.Equal(t, 0, state.PendingCount)
+	})
+
+	t.Run("восстановление использует свой порог", func(t *testing.T) {
+		state := &ServiceStatus{CheckResults: resultsWithSeverity(CRIT)}
+
+		_, pending := ConfirmResults(policy, state, resultsWithSeverity(OK))
+		assert.True(t, pending)
+
+		got, pending := ConfirmResults(policy, state, resultsWithSeverity(OK))
+		assert.False(t, pending)
+		assert.Equal(t, resultsWithSeverity(OK), got)
+	})
+
+	t.Run("смена направления начинает отсчёт заново", func(t *testing.T) {
+		state := &ServiceStatus{CheckResults: resultsWithSeverity(WARN)}
+
+		_, pending := ConfirmResults(policy, state, resultsWithSeverity(CRIT))
+		assert.True(t, pending)
+		_, pending = ConfirmResults(policy, state, resultsWithSeverity(CRIT))
+		assert.True(t, pending)
+
+		_, pending = ConfirmResults(policy, state, resultsWithSeverity(OK))
+		assert.True(t, pending)
+		assert.Equal(t, 1, state.PendingCount)
+	})
+
+	t.Run("порог 1 — поведение без подтверждения", func(t *testing.T) {
+		state := &ServiceStatus{}
+
+		got, pending := ConfirmResults(ConfirmPolicy{Failures: 1, Recoveries: 1}, state, resultsWithSeverity(CRIT))
+		assert.False(t, pending)
+		assert.Equal(t, resultsWithSeverity(CRIT), got)
+	})
+}
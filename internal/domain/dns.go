@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// DNSRecord запись из ответа, Value приведено через config.NormalizeDNSValue.
+type DNSRecord struct {
+	Value string
+	TTL   time.Duration
+}
+
+// dnsValues значения записей через запятую для сообщений.
+func dnsValues(records []DNSRecord) string {
+	if len(records) == 0 {
+		return "пустой ответ"
+	}
+
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Value)
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// NewDNSValuesRule values должны быть приведены через config.NormalizeDNSValue.
+func NewDNSValuesRule(values []string, exact bool) CheckRule {
+	return &DNSValuesRule{
+		values: values,
+		exact:  exact,
+	}
+}
+
+// DNSValuesRule CRIT, если в ответе нет ожидаемого значения, а с exact — и если есть лишнее.
+type DNSValuesRule struct {
+	values []string
+	exact  bool
+}
+
+func (c *DNSValuesRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_DNS_VALUES
+	logger := slog.With("component", component)
+
+	var missing, unexpected []string
+	for _, value := range c.values {
+		if !slices.ContainsFunc(input.DNS, func(record DNSRecord) bool { return record.Value == value }) {
+			missing = append(missing, value)
+		}
+	}
+
+	if c.exact {
+		for _, record := range input.DNS {
+			if !slices.Contains(c.values, record.Value) && !slices.Contains(unexpected, record.Value) {
+				unexpected = append(unexpected, record.Value)
+			}
+		}
+	}
+
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  dnsValues(input.DNS),
+		}
+	}
+
+	logger.Debug("registered error", "missing", missing, "unexpected", unexpected)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("нет значений %s", strings.Join(missing, ", ")))
+	}
+	if len(unexpected) > 0 {
+		problems = append(problems, fmt.Sprintf("лишние значения %s", strings.Join(unexpected, ", ")))
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  fmt.Sprintf("%s; получено: %s", strings.Join(problems, "; "), dnsValues(input.DNS)),
+	}
+}
+
+func NewDNSCountRule(minCount int) CheckRule {
+	return &DNSCountRule{
+		minCount: minCount,
+	}
+}
+
+// DNSCountRule CRIT, если записей в ответе меньше minCount.
+type DNSCountRule struct {
+	minCount int
+}
+
+func (c *DNSCountRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_DNS_COUNT
+	logger := slog.With("component", component)
+
+	if len(input.DNS) >= c.minCount {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
+
+	logger.Debug("registered error", "minCount", c.minCount, "actual", len(input.DNS))
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  fmt.Sprintf("получено записей %d, ожидалось не меньше %d: %s", len(input.DNS), c.minCount, dnsValues(input.DNS)),
+	}
+}
+
+// NewDNSTTLRule нулевая граница не проверяется.
+func NewDNSTTLRule(minTTL time.Duration, maxTTL time.Duration, severity Severity) CheckRule {
+	return &DNSTTLRule{
+		minTTL:   minTTL,
+		maxTTL:   maxTTL,
+		severity: severity,
+	}
+}
+
+// DNSTTLRule severity, если TTL какой-либо записи выходит за границы.
+type DNSTTLRule struct {
+	minTTL   time.Duration
+	maxTTL   time.Duration
+	severity Severity
+}
+
+func (c *DNSTTLRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_DNS_TTL
+	logger := slog.With("component", component)
+
+	var problems []string
+	for _, record := range input.DNS {
+		if c.minTTL > 0 && record.TTL < c.minTTL {
+			problems = append(problems, fmt.Sprintf("TTL %s у %s меньше %s", record.TTL, record.Value, c.minTTL))
+		}
+
+		if c.maxTTL > 0 && record.TTL > c.maxTTL {
+			problems = append(problems, fmt.Sprintf("TTL %s у %s больше %s", record.TTL, record.Value, c.maxTTL))
+		}
+	}
+
+	if len(problems) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+		}
+	}
+
+	logger.Debug("registered error", "minTTL", c.minTTL, "maxTTL", c.maxTTL)
+
+	return CheckResult{
+		RuleType: component,
+		OK:       c.severity,
+		Message:  strings.Join(problems, "; "),
+	}
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// DNSResolver запрашивает записи recordType для host у одного резолвера.
+type DNSResolver interface {
+	Query(ctx context.Context, resolver string, host string, recordType string) ([]DNSRecord, error)
+}
+
+// NewDNSConsistencyRule опрашивает все resolvers и сравнивает наборы значений, TTL не учитывается.
+func NewDNSConsistencyRule(dnsResolver DNSResolver, host string, recordType string, resolvers []string) CheckRule {
+	return &DNSConsistencyRule{
+		dnsResolver: dnsResolver,
+		host:        host,
+		recordType:  recordType,
+		resolvers:   resolvers,
+	}
+}
+
+// DNSConsistencyRule WARN, если резолверы вернули разные ответы или кто-то из них не ответил,
+// CRIT — если не ответил ни один.
+type DNSConsistencyRule struct {
+	dnsResolver DNSResolver
+	host        string
+	recordType  string
+	resolvers   []string
+}
+
+func (c *DNSConsistencyRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_DNS_CONSISTENCY
+	logger := slog.With("component", component, "host", c.host, "record_type", c.recordType)
+
+	answers := make([]string, len(c.resolvers))
+	errs := make([]error, len(c.resolvers))
+	var wg sync.WaitGroup
+	for idx, resolver := range c.resolvers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			records, err := c.dnsResolver.Query(ctx, resolver, c.host, c.recordType)
+			if err != nil {
+				logger.Debug("failed dns query", "resolver", resolver, "err", err)
+				errs[idx] = err
+				return
+			}
+
+			answers[idx] = dnsAnswerSet(records)
+		}()
+	}
+	wg.Wait()
+
+	// резолверы с одинаковым ответом группируются, порядок групп — по первому резолверу
+	var (
+		groups   []string
+		failures []string
+	)
+	resolversByAnswer := map[string][]string{}
+	for idx, answer := range answers {
+		if errs[idx] != nil {
+			failures = append(failures, fmt.Sprintf("%s - %s", c.resolvers[idx], errs[idx]))
+			continue
+		}
+
+		if _, ok := resolversByAnswer[answer]; !ok {
+			groups = append(groups, answer)
+		}
+		resolversByAnswer[answer] = append(resolversByAnswer[answer], c.resolvers[idx])
+	}
+
+	if len(groups) == 0 {
+		logger.Debug("registered error, no resolver answered")
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("ни один резолвер не ответил: %s", strings.Join(failures, "; ")),
+		}
+	}
+
+	if len(groups) == 1 && len(failures) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  fmt.Sprintf("ответы резолверов совпадают: %s", groups[0]),
+		}
+	}
+
+	logger.Debug("registered error", "answers", len(groups), "failures", len(failures))
+
+	details := make([]string, 0, len(groups))
+	for _, answer := range groups {
+		details = append(details, fmt.Sprintf("%s - %s", strings.Join(resolversByAnswer[answer], ", "), answer))
+	}
+
+	message := fmt.Sprintf("ответы резолверов расходятся: %s", strings.Join(details, "; "))
+	if len(groups) == 1 {
+		message = fmt.Sprintf("ответили не все резолверы: %s", details[0])
+	}
+	if len(failures) > 0 {
+		message += fmt.Sprintf("; не ответили: %s", strings.Join(failures, "; "))
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       WARN,
+		Message:  message,
+	}
+}
+
+// dnsAnswerSet отсортированные значения без повторов, чтобы порядок записей в ответе не влиял на сравнение.
+func dnsAnswerSet(records []DNSRecord) string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Value)
+	}
+	slices.Sort(values)
+	values = slices.Compact(values)
+
+	if len(values) == 0 {
+		return "пустой ответ"
+	}
+
+	return strings.Join(values, ", ")
+}
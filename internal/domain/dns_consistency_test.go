@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDNSResolver ответы по адресу резолвера, резолвер без ответа возвращает ошибку.
+type fakeDNSResolver map[string][]DNSRecord
+
+func (r fakeDNSResolver) Query(ctx context.Context, resolver string, host string, recordType string) ([]DNSRecord, error) {
+	records, ok := r[resolver]
+	if !ok {
+		return nil, errors.New("i/o timeout")
+	}
+
+	return records, nil
+}
+
+func TestDNSConsistencyRule(t *testing.T) {
+	resolvers := []string{"10.0.0.1:53", "8.8.8.8:53", "ns1.registrar.ru:53"}
+
+	t.Run("ответы совпадают без учёта порядка и TTL", func(t *testing.T) {
+		rule := NewDNSConsistencyRule(fakeDNSResolver{
+			"10.0.0.1:53":         {{Value: "192.0.2.1", TTL: 300}, {Value: "192.0.2.2", TTL: 300}},
+			"8.8.8.8:53":          {{Value: "192.0.2.2", TTL: 120}, {Value: "192.0.2.1", TTL: 120}},
+			"ns1.registrar.ru:53": {{Value: "192.0.2.1", TTL: 3600}, {Value: "192.0.2.2", TTL: 3600}},
+		}, "example.ru", config.DNS_RECORD_A, resolvers)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, config.TYPE_DNS_CONSISTENCY, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+		assert.Equal(t, "ответы резолверов совпадают: 192.0.2.1, 192.0.2.2", got.Message)
+	})
+
+	t.Run("ответы расходятся", func(t *testing.T) {
+		rule := NewDNSConsistencyRule(fakeDNSResolver{
+			"10.0.0.1:53":         {{Value: "10.1.1.1"}},
+			"8.8.8.8:53":          {{Value: "192.0.2.1"}},
+			"ns1.registrar.ru:53": {{Value: "192.0.2.1"}},
+		}, "example.ru", config.DNS_RECORD_A, resolvers)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(WARN), got.OK)
+		assert.Equal(t, "ответы резолверов расходятся: 10.0.0.1:53 - 10.1.1.1; 8.8.8.8:53, ns1.registrar.ru:53 - 192.0.2.1", got.Message)
+	})
+
+	t.Run("резолвер не ответил", func(t *testing.T) {
+		rule := NewDNSConsistencyRule(fakeDNSResolver{
+			"10.0.0.1:53": {},
+			"8.8.8.8:53":  {},
+		}, "example.ru", config.DNS_RECORD_A, resolvers)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(WARN), got.OK)
+		assert.Equal(t, "ответили не все резолверы: 10.0.0.1:53, 8.8.8.8:53 - пустой ответ; не ответили: ns1.registrar.ru:53 - i/o timeout", got.Message)
+	})
+
+	t.Run("ответы расходятся и резолвер не ответил", func(t *testing.T) {
+		rule := NewDNSConsistencyRule(fakeDNSResolver{
+			"10.0.0.1:53": {{Value: "10.1.1.1"}},
+			"8.8.8.8:53":  {{Value: "192.0.2.1"}},
+		}, "example.ru", config.DNS_RECORD_A, resolvers)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(WARN), got.OK)
+		assert.Equal(t, "ответы резолверов расходятся: 10.0.0.1:53 - 10.1.1.1; 8.8.8.8:53 - 192.0.2.1; не ответили: ns1.registrar.ru:53 - i/o timeout", got.Message)
+	})
+
+	t.Run("ни один резолвер не ответил", func(t *testing.T) {
+		rule := NewDNSConsistencyRule(fakeDNSResolver{}, "example.ru", config.DNS_RECORD_A, resolvers[:2])
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "ни один резолвер не ответил: 10.0.0.1:53 - i/o timeout; 8.8.8.8:53 - i/o timeout", got.Message)
+	})
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSRules(t *testing.T) {
+	records := []DNSRecord{
+		{Value: "192.0.2.1", TTL: 300 * time.Second},
+		{Value: "192.0.2.3", TTL: 30 * time.Second},
+	}
+
+	t.Run("значения без exact", func(t *testing.T) {
+		got := NewDNSValuesRule([]string{"192.0.2.1"}, false).Check(t.Context(), &CheckInput{DNS: records})
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("лишние и недостающие значения", func(t *testing.T) {
+		got := NewDNSValuesRule([]string{"192.0.2.1", "192.0.2.2"}, true).Check(t.Context(), &CheckInput{DNS: records})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "нет значений 192.0.2.2; лишние значения 192.0.2.3; получено: 192.0.2.1, 192.0.2.3", got.Message)
+	})
+
+	t.Run("пустой ответ", func(t *testing.T) {
+		got := NewDNSCountRule(1).Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "получено записей 0, ожидалось не меньше 1: пустой ответ", got.Message)
+	})
+
+	t.Run("TTL за границами", func(t *testing.T) {
+		got := NewDNSTTLRule(time.Minute, 200*time.Second, WARN).Check(t.Context(), &CheckInput{DNS: records})
+		assert.Equal(t, Severity(WARN), got.OK)
+		assert.Equal(t, "TTL 5m0s у 192.0.2.1 больше 3m20s; TTL 30s у 192.0.2.3 меньше 1m0s", got.Message)
+	})
+
+	t.Run("TTL за границами с уровнем crit", func(t *testing.T) {
+		got := NewDNSTTLRule(time.Minute, 0, CRIT).Check(t.Context(), &CheckInput{DNS: records})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "TTL 30s у 192.0.2.3 меньше 1m0s", got.Message)
+	})
+
+	t.Run("TTL только с нижней границей", func(t *testing.T) {
+		got := NewDNSTTLRule(10*time.Second, 0, WARN).Check(t.Context(), &CheckInput{DNS: records})
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+}
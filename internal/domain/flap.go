@@ -0,0 +1,75 @@
+package domain
+
+// FlapPolicy настройки определения «флаппинга» по алгоритму Nagios: по последним Window
+// состояниям считается взвешенная доля смен состояния, свежие смены весят больше старых.
+type FlapPolicy struct {
+	Enabled       bool
+	Window        int
+	LowThreshold  float64
+	HighThreshold float64
+}
+
+type FlapTransition int
+
+const (
+	FlapNone FlapTransition = iota
+	FlapStarted
+	FlapStopped
+)
+
+const (
+	flapOldestWeight = 0.8
+	flapNewestWeight = 1.2
+)
+
+// DetectFlapping добавляет severity в историю состояний сервиса, пересчитывает state.FlapScore
+// и переключает state.Flapping: вход — когда оценка достигла HighThreshold, выход — когда опустилась ниже LowThreshold.
+func DetectFlapping(policy FlapPolicy, state *ServiceStatus, severity Severity) FlapTransition {
+	if !policy.Enabled {
+		return FlapNone
+	}
+
+	state.StateHistory = append(state.StateHistory, severity)
+	if len(state.StateHistory) > policy.Window {
+		state.StateHistory = state.StateHistory[len(state.StateHistory)-policy.Window:]
+	}
+
+	state.FlapScore = FlapScore(state.StateHistory, policy.Window)
+
+	if !state.Flapping && state.FlapScore >= policy.HighThreshold {
+		state.Flapping = true
+		return FlapStarted
+	}
+
+	if state.Flapping && state.FlapScore < policy.LowThreshold {
+		state.Flapping = false
+		return FlapStopped
+	}
+
+	return FlapNone
+}
+
+// FlapScore процент смен состояния в истории от 0 до 100. Вес смены линейно растёт
+// от 0.8 для самой старой до 1.2 для самой свежей, знаменатель — число переходов в полном окне,
+// поэтому пока история не накоплена, оценка занижена.
+func FlapScore(history []Severity, window int) float64 {
+	transitions := window - 1
+	if transitions < 1 {
+		return 0
+	}
+
+	// выравниваем историю по правому краю окна, чтобы свежие смены получали больший вес
+	offset := window - len(history)
+
+	var weighted float64
+	for i := 1; i < len(history); i++ {
+		if history[i] == history[i-1] {
+			continue
+		}
+
+		position := offset + i - 1
+		weighted += flapOldestWeight + (flapNewestWeight-flapOldestWeight)*float64(position)/float64(max(transitions-1, 1))
+	}
+
+	return weighted * 100 / float64(transitions)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlapScore(t *testing.T) {
+	t.Run("стабильная история — 0%", func(t *testing.T) {
+		history := make([]Severity, 21)
+		assert.Equal(t, 0.0, FlapScore(history, 21))
+	})
+
+	t.Run("смена на каждой проверке — 100%", func(t *testing.T) {
+		var history []Severity
+		for i := range 21 {
+			history = append(history, Severity(i%2*2))
+		}
+		assert.InDelta(t, 100.0, FlapScore(history, 21), 0.001)
+	})
+
+	t.Run("свежая смена весит больше старой", func(t *testing.T) {
+		oldChange := []Severity{CRIT, OK, OK, OK, OK}
+		newChange := []Severity{OK, OK, OK, OK, CRIT}
+
+		assert.InDelta(t, 20.0, FlapScore(oldChange, 5), 0.001)
+		assert.InDelta(t, 30.0, FlapScore(newChange, 5), 0.001)
+	})
+}
+
+func TestDetectFlapping(t *testing.T) {
+	policy := FlapPolicy{Enabled: true, Window: 5, LowThreshold: 25, HighThreshold: 50}
+
+	t.Run("выключено — история не копится", func(t *testing.T) {
+		state := &ServiceStatus{}
+		assert.Equal(t, FlapNone, DetectFlapping(FlapPolicy{}, state, CRIT))
+		assert.Empty(t, state.StateHistory)
+	})
+
+	t.Run("вход и выход из флаппинга", func(t *testing.T) {
+		state := &ServiceStatus{}
+
+		var transitions []FlapTransition
+		for _, severity := range []Severity{OK, CRIT, OK} {
+			transitions = append(transitions, DetectFlapping(policy, state, severity))
+		}
+		assert.Equal(t, []FlapTransition{FlapNone, FlapNone, FlapStarted}, transitions)
+		assert.True(t, state.Flapping)
+
+		// пока смены остаются в окне, флаппинг продолжается
+		for range 3 {
+			assert.Equal(t, FlapNone, DetectFlapping(policy, state, CRIT))
+		}
+		assert.True(t, state.Flapping)
+
+		assert.Equal(t, FlapStopped, DetectFlapping(policy, state, CRIT))
+		assert.False(t, state.Flapping)
+		assert.Len(t, state.StateHistory, 5)
+	})
+}
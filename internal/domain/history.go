@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// HistoryRecord исход одной проверки сервиса, до подтверждения смены состояния.
+// Если запрос к сервису не удался, Latency и StatusCode нулевые, а ошибка записана в Results.
+type HistoryRecord struct {
+	ServiceName string
+	CheckedAt   time.Time
+	Latency     time.Duration
+	StatusCode  int
+	Severity    Severity
+	Results     []CheckResult
+}
+
+// ResultStore история проверок сервисов.
+type ResultStore interface {
+	Append(ctx context.Context, record HistoryRecord) error
+	// Query возвращает записи сервиса с CheckedAt в интервале [from, to] по возрастанию времени.
+	Query(ctx context.Context, serviceName string, from, to time.Time) ([]HistoryRecord, error)
+	// Prune удаляет записи старше before.
+	Prune(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/kias-hack/web-watcher/internal/config"
+	"golang.org/x/net/html"
+)
+
+// HTMLExpectation что проверяется у элементов, найденных селектором. Без Count, Text и Pattern
+// достаточно одного найденного элемента.
+type HTMLExpectation struct {
+	Count         *int
+	CountOperator string // пустой означает eq
+	Attribute     string // пустой — проверяется текст элемента
+	Text          string
+	Pattern       string
+	Negate        bool
+}
+
+// NewHTMLSelectorRule selector и pattern должны быть проверены при загрузке конфига.
+func NewHTMLSelectorRule(selector string, expectation HTMLExpectation) CheckRule {
+	rule := &HTMLSelectorRule{
+		source:        selector,
+		selector:      cascadia.MustCompile(selector),
+		count:         expectation.Count,
+		countOperator: expectation.CountOperator,
+		attribute:     expectation.Attribute,
+		text:          expectation.Text,
+		negate:        expectation.Negate,
+	}
+
+	if expectation.Pattern != "" {
+		rule.re = regexp.MustCompile(expectation.Pattern)
+	}
+
+	if rule.count == nil && rule.text == "" && rule.re == nil {
+		atLeast := 0
+		rule.count = &atLeast
+		rule.countOperator = config.JSON_OP_GT
+	}
+
+	return rule
+}
+
+type HTMLSelectorRule struct {
+	source   string
+	selector cascadia.Selector
+
+	count         *int
+	countOperator string
+
+	attribute string
+	text      string
+	re        *regexp.Regexp
+	negate    bool
+}
+
+func (c *HTMLSelectorRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_HTML_SELECTOR
+	logger := slog.With("component", component)
+
+	doc, err := html.Parse(strings.NewReader(bodyAsUTF8(input)))
+	if err != nil {
+		logger.Debug("failed parse html", "err", err)
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  "ошибка парсинга тела сообщения",
+		}
+	}
+
+	nodes := c.selector.MatchAll(doc)
+
+	if c.count != nil && !compareCount(c.countOperator, len(nodes), *c.count) {
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("найдено элементов %s: %d, ожидается %s %d", c.source, len(nodes), countOperatorSymbol(c.countOperator), *c.count),
+		}
+	}
+
+	if c.text != "" || c.re != nil {
+		if message, ok := c.checkValues(nodes); !ok {
+			return CheckResult{
+				RuleType: component,
+				OK:       CRIT,
+				Message:  message,
+			}
+		}
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       OK,
+		Message:  fmt.Sprintf("найдено элементов %s: %d", c.source, len(nodes)),
+	}
+}
+
+// checkValues без negate значение каждого элемента должно совпасть, с negate — ни одного.
+func (c *HTMLSelectorRule) checkValues(nodes []*html.Node) (string, bool) {
+	subject := "текст " + c.source
+	if c.attribute != "" {
+		subject = fmt.Sprintf("атрибут %s у %s", c.attribute, c.source)
+	}
+
+	if len(nodes) == 0 {
+		if c.negate {
+			return "", true
+		}
+		return fmt.Sprintf("не найдены элементы по селектору %s", c.source), false
+	}
+
+	for _, node := range nodes {
+		value, ok := c.value(node)
+		if !ok {
+			if c.negate {
+				continue
+			}
+			return fmt.Sprintf("%s отсутствует", subject), false
+		}
+
+		matched := c.matchValue(value)
+		switch {
+		case c.negate && matched && c.re != nil:
+			return fmt.Sprintf("%s: найдено совпадение с выражением %s - %s", subject, c.re, truncate(value, 100)), false
+		case c.negate && matched:
+			return fmt.Sprintf("%s: найдено значение '%s'", subject, truncate(value, 100)), false
+		case !c.negate && !matched && c.re != nil:
+			return fmt.Sprintf("%s: '%s' не совпадает с выражением %s", subject, truncate(value, 100), c.re), false
+		case !c.negate && !matched:
+			return fmt.Sprintf("%s: '%s', ожидается '%s'", subject, truncate(value, 100), c.text), false
+		}
+	}
+
+	return "", true
+}
+
+func (c *HTMLSelectorRule) value(node *html.Node) (string, bool) {
+	if c.attribute == "" {
+		return strings.Join(strings.Fields(nodeText(node)), " "), true
+	}
+
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, c.attribute) {
+			return attr.Val, true
+		}
+	}
+
+	return "", false
+}
+
+func (c *HTMLSelectorRule) matchValue(value string) bool {
+	if c.re != nil {
+		return c.re.MatchString(value)
+	}
+
+	return value == c.text
+}
+
+// nodeText аналог textContent из DOM: текст всех потомков без разделителей.
+func nodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(nodeText(child))
+	}
+
+	return sb.String()
+}
+
+func compareCount(operator string, actual int, expected int) bool {
+	switch operator {
+	case config.JSON_OP_NE:
+		return actual != expected
+	case config.JSON_OP_LT:
+		return actual < expected
+	case config.JSON_OP_LTE:
+		return actual <= expected
+	case config.JSON_OP_GT:
+		return actual > expected
+	case config.JSON_OP_GTE:
+		return actual >= expected
+	default:
+		return actual == expected
+	}
+}
+
+func countOperatorSymbol(operator string) string {
+	if symbol, ok := jsonOperatorSymbols[operator]; ok {
+		return symbol
+	}
+
+	return "="
+}
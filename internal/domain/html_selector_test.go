@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLSelectorRule(t *testing.T) {
+	page := &CheckInput{
+		Response: &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": []string{"text/html"}}},
+		Body: []byte(`<html><head>
+			<title> Каталог  товаров </title>
+			<meta name="robots" content="index, follow">
+		</head><body>
+			<span class="price">100 <small>₽</small></span>
+			<span class="price">200 ₽</span>
+		</body></html>`),
+	}
+	count := func(n int) *int { return &n }
+
+	t.Run("без условий достаточно одного элемента", func(t *testing.T) {
+		got := NewHTMLSelectorRule(".price", HTMLExpectation{}).Check(t.Context(), page)
+		assert.Equal(t, config.TYPE_HTML_SELECTOR, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+		assert.Equal(t, "найдено элементов .price: 2", got.Message)
+
+		got = NewHTMLSelectorRule(".discount", HTMLExpectation{}).Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "найдено элементов .discount: 0, ожидается > 0", got.Message)
+	})
+
+	t.Run("ровно один title с точным текстом", func(t *testing.T) {
+		rule := NewHTMLSelectorRule("title", HTMLExpectation{Count: count(1), Text: "Каталог товаров"})
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), page).OK)
+
+		rule = NewHTMLSelectorRule("title", HTMLExpectation{Text: "Главная"})
+		got := rule.Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "текст title: 'Каталог товаров', ожидается 'Главная'", got.Message)
+	})
+
+	t.Run("число элементов с оператором", func(t *testing.T) {
+		rule := NewHTMLSelectorRule(".price", HTMLExpectation{Count: count(2), CountOperator: config.JSON_OP_LT})
+		got := rule.Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "найдено элементов .price: 2, ожидается < 2", got.Message)
+	})
+
+	t.Run("текст каждого элемента по выражению", func(t *testing.T) {
+		rule := NewHTMLSelectorRule(".price", HTMLExpectation{Pattern: `^\d+ ₽$`})
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), page).OK)
+
+		rule = NewHTMLSelectorRule(".price", HTMLExpectation{Pattern: `^1\d\d`})
+		got := rule.Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "текст .price: '200 ₽' не совпадает с выражением ^1\\d\\d", got.Message)
+	})
+
+	t.Run("robots не должен содержать noindex", func(t *testing.T) {
+		rule := NewHTMLSelectorRule("meta[name=robots]", HTMLExpectation{Attribute: "content", Pattern: "noindex", Negate: true})
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), page).OK)
+
+		closed := &CheckInput{Body: []byte(`<meta name="robots" content="noindex, nofollow">`)}
+		got := rule.Check(t.Context(), closed)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "атрибут content у meta[name=robots]: найдено совпадение с выражением noindex - noindex, nofollow", got.Message)
+
+		// нет тега — нет и запрещённого значения
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), &CheckInput{Body: []byte(`<p></p>`)}).OK)
+	})
+
+	t.Run("отсутствующий атрибут", func(t *testing.T) {
+		rule := NewHTMLSelectorRule("meta[name=robots]", HTMLExpectation{Attribute: "data-id", Text: "1"})
+		got := rule.Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "атрибут data-id у meta[name=robots] отсутствует", got.Message)
+	})
+
+	t.Run("элементы не найдены для проверки текста", func(t *testing.T) {
+		rule := NewHTMLSelectorRule("h1", HTMLExpectation{Text: "Каталог"})
+		got := rule.Check(t.Context(), page)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "не найдены элементы по селектору h1", got.Message)
+	})
+
+	t.Run("страница в windows-1251", func(t *testing.T) {
+		// «Привет» в windows-1251
+		body := append([]byte(`<html><head><meta charset="windows-1251"></head><body><h1>`), 0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2)
+		body = append(body, []byte(`</h1></body></html>`)...)
+		input := &CheckInput{Response: &http.Response{StatusCode: 200}, Body: body}
+
+		rule := NewHTMLSelectorRule("h1", HTMLExpectation{Text: "Привет"})
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), input).OK)
+	})
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+var jsonOperatorSymbols = map[string]string{
+	config.JSON_OP_NE:     "!=",
+	config.JSON_OP_LT:     "<",
+	config.JSON_OP_LTE:    "<=",
+	config.JSON_OP_GT:     ">",
+	config.JSON_OP_GTE:    ">=",
+	config.JSON_OP_IN:     "in",
+	config.JSON_OP_REGEX:  "~",
+	config.JSON_OP_LEN_EQ: "длина =",
+	config.JSON_OP_LEN_GT: "длина >",
+}
+
+// jsonCondition условие для значения json_field: оператор и операнд из конфига.
+type jsonCondition struct {
+	operator string
+	operand  any
+	re       *regexp.Regexp
+}
+
+// newJSONCondition операнд должен быть проверен при загрузке конфига.
+func newJSONCondition(operator string, operand any) *jsonCondition {
+	condition := &jsonCondition{operator: operator, operand: operand}
+	if operator == config.JSON_OP_REGEX {
+		condition.re = regexp.MustCompile(operand.(string))
+	}
+
+	return condition
+}
+
+// match возвращает ошибку, если значение не подходит оператору, например сравнение строки с числом.
+func (c *jsonCondition) match(value gjson.Result) (bool, error) {
+	switch c.operator {
+	case config.JSON_OP_EQ, "":
+		return jsonEqual(value.Value(), c.operand), nil
+	case config.JSON_OP_NE:
+		return !jsonEqual(value.Value(), c.operand), nil
+	case config.JSON_OP_LT, config.JSON_OP_LTE, config.JSON_OP_GT, config.JSON_OP_GTE:
+		if value.Type != gjson.Number {
+			return false, fmt.Errorf("значение %v не число", value.Value())
+		}
+
+		actual, limit := value.Float(), toFloat(c.operand)
+		switch c.operator {
+		case config.JSON_OP_LT:
+			return actual < limit, nil
+		case config.JSON_OP_LTE:
+			return actual <= limit, nil
+		case config.JSON_OP_GT:
+			return actual > limit, nil
+		default:
+			return actual >= limit, nil
+		}
+	case config.JSON_OP_IN:
+		items, _ := c.operand.([]any)
+		for _, item := range items {
+			if jsonEqual(value.Value(), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case config.JSON_OP_REGEX:
+		return c.re.MatchString(value.String()), nil
+	case config.JSON_OP_LEN_EQ, config.JSON_OP_LEN_GT:
+		length, err := jsonLength(value)
+		if err != nil {
+			return false, err
+		}
+
+		if c.operator == config.JSON_OP_LEN_EQ {
+			return length == int(toFloat(c.operand)), nil
+		}
+		return length > int(toFloat(c.operand)), nil
+	}
+
+	return false, fmt.Errorf("неизвестный оператор %s", c.operator)
+}
+
+func (c *jsonCondition) String() string {
+	return fmt.Sprintf("%s %v", jsonOperatorSymbols[c.operator], c.operand)
+}
+
+// jsonLength длина массива, строки в символах или число ключей объекта.
+func jsonLength(value gjson.Result) (int, error) {
+	switch {
+	case value.IsArray():
+		return len(value.Array()), nil
+	case value.IsObject():
+		return len(value.Map()), nil
+	case value.Type == gjson.String:
+		return utf8.RuneCountInString(value.String()), nil
+	}
+
+	return 0, fmt.Errorf("у значения %v нет длины", value.Value())
+}
+
+// jsonEqual сравнивает значения после приведения чисел к float64: gjson отдаёт float64, а TOML — int64.
+func jsonEqual(actual any, expected any) bool {
+	return reflect.DeepEqual(normalizeJSONValue(actual), normalizeJSONValue(expected))
+}
+
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case int64, int, float32:
+		return toFloat(v)
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = normalizeJSONValue(item)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = normalizeJSONValue(item)
+		}
+		return result
+	}
+
+	return value
+}
+
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+
+	return 0
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// NewJSONSchemaRule проверяет тело ответа по схеме, в сообщение попадают первые maxErrors нарушений.
+func NewJSONSchemaRule(schema *jsonschema.Schema, maxErrors int) CheckRule {
+	return &JSONSchemaRule{
+		schema:    schema,
+		maxErrors: maxErrors,
+	}
+}
+
+type JSONSchemaRule struct {
+	schema    *jsonschema.Schema
+	maxErrors int
+}
+
+func (c *JSONSchemaRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_JSON_SCHEMA
+	logger := slog.With("component", component)
+
+	body, err := jsonschema.UnmarshalJSON(bytes.NewReader(input.Body))
+	if err != nil {
+		logger.Debug("response format of body not is json", "err", err)
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  "ошибка парсинга тела сообщения",
+		}
+	}
+
+	err = c.schema.Validate(body)
+	if err == nil {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  "ответ соответствует схеме",
+		}
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		logger.Error("failed validate body", "err", err)
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("ошибка проверки по схеме - %s", err),
+		}
+	}
+
+	// порядок ошибок в библиотеке зависит от обхода map, сортируем, чтобы сообщение не менялось между проверками
+	violations := schemaViolations(validationErr, message.NewPrinter(language.English))
+	slices.Sort(violations)
+
+	shown := violations
+	if len(shown) > c.maxErrors {
+		shown = shown[:c.maxErrors]
+	}
+
+	text := fmt.Sprintf("ответ не соответствует схеме: %s", strings.Join(shown, "; "))
+	if rest := len(violations) - len(shown); rest > 0 {
+		text += fmt.Sprintf(" и ещё %d", rest)
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  text,
+	}
+}
+
+// schemaViolations собирает конечные ошибки дерева в виде "#/указатель - описание",
+// промежуточные узлы вроде "validation failed" только группируют вложенные.
+func schemaViolations(err *jsonschema.ValidationError, printer *message.Printer) []string {
+	if len(err.Causes) == 0 {
+		return []string{fmt.Sprintf("%s - %s", jsonPointer(err.InstanceLocation), err.ErrorKind.LocalizedString(printer))}
+	}
+
+	var result []string
+	for _, cause := range err.Causes {
+		result = append(result, schemaViolations(cause, printer)...)
+	}
+
+	return result
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointer форматирует путь как фрагмент URI по RFC 6901, корень документа — "#".
+func jsonPointer(location []string) string {
+	var sb strings.Builder
+	sb.WriteString("#")
+	for _, token := range location {
+		sb.WriteString("/")
+		sb.WriteString(jsonPointerEscaper.Replace(token))
+	}
+
+	return sb.String()
+}
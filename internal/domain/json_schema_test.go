@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileTestSchema(t *testing.T, schema string) *jsonschema.Schema {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource("schema.json", doc))
+
+	compiled, err := compiler.Compile("schema.json")
+	require.NoError(t, err)
+
+	return compiled
+}
+
+func TestJSONSchemaRule(t *testing.T) {
+	schema := compileTestSchema(t, `{
+		"type": "object",
+		"required": ["status"],
+		"properties": {
+			"status": {"enum": ["ok", "degraded"]},
+			"items": {"type": "array", "items": {"type": "integer", "minimum": 1}}
+		}
+	}`)
+
+	t.Run("ответ соответствует схеме", func(t *testing.T) {
+		rule := NewJSONSchemaRule(schema, 5)
+
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte(`{"status": "ok", "items": [1, 2]}`)})
+		assert.Equal(t, config.TYPE_JSON_SCHEMA, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("нарушения выводятся с указателями", func(t *testing.T) {
+		rule := NewJSONSchemaRule(schema, 5)
+
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte(`{"items": [1, 0]}`)})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "ответ не соответствует схеме: # - missing property 'status'; #/items/1 - minimum: got 0, want 1", got.Message)
+	})
+
+	t.Run("выводятся только первые нарушения", func(t *testing.T) {
+		rule := NewJSONSchemaRule(schema, 1)
+
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte(`{"status": "down", "items": [0, "x"]}`)})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "ответ не соответствует схеме: #/items/0 - minimum: got 0, want 1 и ещё 2", got.Message)
+	})
+
+	t.Run("тело не json", func(t *testing.T) {
+		rule := NewJSONSchemaRule(schema, 5)
+
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte(`<html></html>`)})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "ошибка парсинга тела сообщения", got.Message)
+	})
+}
+
+func TestJSONPointer(t *testing.T) {
+	assert.Equal(t, "#", jsonPointer(nil))
+	assert.Equal(t, "#/a~1b/c~0d/0", jsonPointer([]string{"a/b", "c~d", "0"}))
+}
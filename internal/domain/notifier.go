@@ -6,7 +6,7 @@ import (
 )
 
 type Notifier interface {
-	Notify(ctx context.Context, alert *AlertEvent)
+	Notify(ctx context.Context, alert *AlertEvent) error
 }
 
 type AlertRule struct {
@@ -18,14 +18,39 @@ type AlertRule struct {
 }
 
 type RoutedNotifier struct {
+	Name     string
 	Rule     AlertRule
 	Notifier Notifier
 }
 
+type EventKind int
+
+const (
+	EventAlert EventKind = iota
+	EventFlappingStarted
+	EventFlappingStopped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventAlert:
+		return "alert"
+	case EventFlappingStarted:
+		return "flapping_started"
+	case EventFlappingStopped:
+		return "flapping_stopped"
+	}
+
+	return "unknown"
+}
+
 type AlertEvent struct {
+	Kind        EventKind
 	ServiceName string
 	Status      Severity
 	Results     []CheckResult
+	CheckedAt   time.Time
+	FlapScore   float64
 }
 
 func CanSendNotify(rule AlertRule, checkResults []CheckResult, oldState ServiceStatus, now time.Time) bool {
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxEntry событие, которое не удалось доставить получателю Notifier.
+type OutboxEntry struct {
+	ID        string
+	Notifier  string
+	Event     AlertEvent
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+}
+
+// Outbox хранилище недоставленных событий. Записи переживают перезапуск
+// и переотправляются при старте, а окончательно недоставленные уходят в dead-letter.
+type Outbox interface {
+	Put(ctx context.Context, entry OutboxEntry) error
+	List(ctx context.Context) ([]OutboxEntry, error)
+	Remove(ctx context.Context, id string) error
+	DeadLetter(ctx context.Context, entry OutboxEntry) error
+}
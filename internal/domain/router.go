@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+)
+
+// NewCheckerRouter выбирает ServiceChecker по типу сервиса.
+func NewCheckerRouter(checkers map[string]ServiceChecker) ServiceChecker {
+	return &CheckerRouter{checkers: checkers}
+}
+
+type CheckerRouter struct {
+	checkers map[string]ServiceChecker
+}
+
+func (r *CheckerRouter) ServiceCheck(ctx context.Context, service *Service) (*CheckReport, error) {
+	checker, ok := r.checkers[service.Type]
+	if !ok {
+		return nil, fmt.Errorf("no checker for service type %s", service.Type)
+	}
+
+	return checker.ServiceCheck(ctx, service)
+}
@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// SecurityHeadersPolicy проверяются только пункты из Severities (ключи config.SECURITY_HEADER_*).
+type SecurityHeadersPolicy struct {
+	HSTSMinMaxAge         int
+	HSTSIncludeSubDomains bool
+	HSTSPreload           bool
+	Severities            map[string]Severity
+}
+
+// версия в значении вроде nginx/1.18.0 или PHP/8.1
+var versionLeakRe = regexp.MustCompile(`/\s*v?\d|\d+\.\d+`)
+
+func NewSecurityHeadersRule(policy SecurityHeadersPolicy) CheckRule {
+	return &SecurityHeadersRule{policy: policy}
+}
+
+type SecurityHeadersRule struct {
+	policy SecurityHeadersPolicy
+}
+
+func (c *SecurityHeadersRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_SECURITY_HEADERS
+	logger := slog.With("component", component)
+
+	var (
+		findings []string
+		severity = OK
+	)
+
+	// пункты обходятся в фиксированном порядке, чтобы сообщение не менялось между проверками
+	for _, item := range config.SecurityHeaderItems {
+		itemSeverity, ok := c.policy.Severities[item]
+		if !ok {
+			continue
+		}
+
+		for _, finding := range c.audit(item, input) {
+			findings = append(findings, fmt.Sprintf("%s (%s)", finding, itemSeverity))
+			severity = max(severity, itemSeverity)
+		}
+	}
+
+	if len(findings) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  "заголовки безопасности в порядке",
+		}
+	}
+
+	logger.Debug("registered error, security headers findings", "findings", findings)
+
+	return CheckResult{
+		RuleType: component,
+		OK:       severity,
+		Message:  strings.Join(findings, "; "),
+	}
+}
+
+func (c *SecurityHeadersRule) audit(item string, input *CheckInput) []string {
+	header := input.Response.Header
+
+	switch item {
+	case config.SECURITY_HEADER_HSTS:
+		// браузеры учитывают HSTS только по https
+		if input.Response.TLS == nil {
+			return nil
+		}
+		return c.auditHSTS(firstHeaderValue(header, "Strict-Transport-Security"))
+	case config.SECURITY_HEADER_CSP:
+		if firstHeaderValue(header, "Content-Security-Policy") == "" {
+			return []string{"нет заголовка Content-Security-Policy"}
+		}
+	case config.SECURITY_HEADER_CONTENT_TYPE_OPTIONS:
+		value := firstHeaderValue(header, "X-Content-Type-Options")
+		if value == "" {
+			return []string{"нет заголовка X-Content-Type-Options"}
+		}
+		if !strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+			return []string{fmt.Sprintf("X-Content-Type-Options: '%s', ожидается nosniff", value)}
+		}
+	case config.SECURITY_HEADER_FRAME_OPTIONS:
+		value := strings.TrimSpace(firstHeaderValue(header, "X-Frame-Options"))
+		if strings.EqualFold(value, "DENY") || strings.EqualFold(value, "SAMEORIGIN") {
+			return nil
+		}
+		if hasCSPDirective(firstHeaderValue(header, "Content-Security-Policy"), "frame-ancestors") {
+			return nil
+		}
+		if value != "" {
+			return []string{fmt.Sprintf("X-Frame-Options: '%s', ожидается DENY или SAMEORIGIN", value)}
+		}
+		return []string{"нет X-Frame-Options и frame-ancestors в Content-Security-Policy"}
+	case config.SECURITY_HEADER_REFERRER_POLICY:
+		if firstHeaderValue(header, "Referrer-Policy") == "" {
+			return []string{"нет заголовка Referrer-Policy"}
+		}
+	case config.SECURITY_HEADER_VERSION_LEAK:
+		var findings []string
+		for _, name := range []string{"Server", "X-Powered-By"} {
+			if value := firstHeaderValue(header, name); versionLeakRe.MatchString(value) {
+				findings = append(findings, fmt.Sprintf("%s раскрывает версию - %s", name, value))
+			}
+		}
+		return findings
+	}
+
+	return nil
+}
+
+func (c *SecurityHeadersRule) auditHSTS(value string) []string {
+	if value == "" {
+		return []string{"нет заголовка Strict-Transport-Security"}
+	}
+
+	maxAge := -1
+	var includeSubDomains, preload bool
+	for _, directive := range strings.Split(value, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(strings.TrimSpace(arg), `"`)); err == nil {
+				maxAge = seconds
+			}
+		case "includesubdomains":
+			includeSubDomains = true
+		case "preload":
+			preload = true
+		}
+	}
+
+	var findings []string
+	if maxAge < 0 {
+		findings = append(findings, "Strict-Transport-Security: нет max-age")
+	} else if maxAge < c.policy.HSTSMinMaxAge {
+		findings = append(findings, fmt.Sprintf("Strict-Transport-Security: max-age=%d меньше %d", maxAge, c.policy.HSTSMinMaxAge))
+	}
+
+	if c.policy.HSTSIncludeSubDomains && !includeSubDomains {
+		findings = append(findings, "Strict-Transport-Security: нет includeSubDomains")
+	}
+
+	if c.policy.HSTSPreload && !preload {
+		findings = append(findings, "Strict-Transport-Security: нет preload")
+	}
+
+	return findings
+}
+
+func firstHeaderValue(header http.Header, name string) string {
+	values, _ := headerValues(header, name)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func hasCSPDirective(policy string, directive string) bool {
+	for _, part := range strings.Split(policy, ";") {
+		fields := strings.Fields(part)
+		if len(fields) > 0 && strings.EqualFold(fields[0], directive) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersRule(t *testing.T) {
+	allWarn := map[string]Severity{}
+	for _, item := range config.SecurityHeaderItems {
+		allWarn[item] = WARN
+	}
+
+	secure := http.Header{
+		"Strict-Transport-Security": []string{"max-age=31536000; includeSubDomains; preload"},
+		"Content-Security-Policy":   []string{"default-src 'self'; frame-ancestors 'none'"},
+		"X-Content-Type-Options":    []string{"nosniff"},
+		"Referrer-Policy":           []string{"strict-origin-when-cross-origin"},
+		"Server":                    []string{"nginx"},
+	}
+
+	t.Run("все пункты выполнены", func(t *testing.T) {
+		rule := NewSecurityHeadersRule(SecurityHeadersPolicy{
+			HSTSMinMaxAge:         config.DEFAULT_HSTS_MIN_MAX_AGE,
+			HSTSIncludeSubDomains: true,
+			HSTSPreload:           true,
+			Severities:            allWarn,
+		})
+
+		got := rule.Check(t.Context(), &CheckInput{Response: &http.Response{Header: secure, TLS: &tls.ConnectionState{}}})
+		assert.Equal(t, config.TYPE_SECURITY_HEADERS, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("каждое нарушение перечислено со своим уровнем", func(t *testing.T) {
+		severities := map[string]Severity{}
+		for item, severity := range allWarn {
+			severities[item] = severity
+		}
+		severities[config.SECURITY_HEADER_CSP] = CRIT
+
+		rule := NewSecurityHeadersRule(SecurityHeadersPolicy{
+			HSTSMinMaxAge:         config.DEFAULT_HSTS_MIN_MAX_AGE,
+			HSTSIncludeSubDomains: true,
+			Severities:            severities,
+		})
+
+		header := http.Header{
+			"Strict-Transport-Security": []string{"max-age=300"},
+			"X-Content-Type-Options":    []string{"sniff"},
+			"X-Frame-Options":           []string{"ALLOW-FROM https://example.ru"},
+			"Server":                    []string{"nginx/1.18.0"},
+			"X-Powered-By":              []string{"PHP/8.1"},
+		}
+
+		got := rule.Check(t.Context(), &CheckInput{Response: &http.Response{Header: header, TLS: &tls.ConnectionState{}}})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "Strict-Transport-Security: max-age=300 меньше 15768000 (warn); "+
+			"Strict-Transport-Security: нет includeSubDomains (warn); "+
+			"нет заголовка Content-Security-Policy (crit); "+
+			"X-Content-Type-Options: 'sniff', ожидается nosniff (warn); "+
+			"X-Frame-Options: 'ALLOW-FROM https://example.ru', ожидается DENY или SAMEORIGIN (warn); "+
+			"нет заголовка Referrer-Policy (warn); "+
+			"Server раскрывает версию - nginx/1.18.0 (warn); "+
+			"X-Powered-By раскрывает версию - PHP/8.1 (warn)", got.Message)
+	})
+
+	t.Run("отключённые пункты и hsts без tls не проверяются", func(t *testing.T) {
+		rule := NewSecurityHeadersRule(SecurityHeadersPolicy{
+			HSTSMinMaxAge: config.DEFAULT_HSTS_MIN_MAX_AGE,
+			Severities: map[string]Severity{
+				config.SECURITY_HEADER_HSTS:          CRIT,
+				config.SECURITY_HEADER_FRAME_OPTIONS: WARN,
+			},
+		})
+
+		header := http.Header{"X-Frame-Options": []string{"sameorigin"}}
+
+		got := rule.Check(t.Context(), &CheckInput{Response: &http.Response{Header: header}})
+		assert.Equal(t, Severity(OK), got.OK)
+
+		got = rule.Check(t.Context(), &CheckInput{Response: &http.Response{Header: header, TLS: &tls.ConnectionState{}}})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "нет заголовка Strict-Transport-Security (crit)", got.Message)
+	})
+}
@@ -7,16 +7,74 @@ import (
 
 type Service struct {
 	Name     string
+	Type     string // config.SERVICE_TYPE_*, по нему выбирается ServiceChecker
 	URL      string
 	Interval time.Duration
 	Rules    []CheckRule
+	Confirm  ConfirmPolicy
+	Flap     FlapPolicy
+
+	// Address host:port для сервисов без url
+	Address string
+	TLS     TLSEndpoint
+	TCP     TCPExchange
+	DNS     DNSQuery
+}
+
+// DNSQuery запрос для сервиса типа dns.
+type DNSQuery struct {
+	Host       string
+	RecordType string   // config.DNS_RECORD_*
+	Resolvers  []string // host:port, опрашиваются по очереди до первого ответа
+}
+
+// TCPExchange обмен данными для сервиса типа tcp.
+type TCPExchange struct {
+	Send   string // отправляется сразу после подключения, пустой — ничего не отправлять
+	Expect string // регулярное выражение для ответа или баннера, пустое — ответ не читается
+}
+
+// TLSEndpoint параметры рукопожатия для сервиса типа tls, для http используется только SkipVerify.
+type TLSEndpoint struct {
+	StartTLS   string // config.STARTTLS_*, пустой — TLS сразу после подключения
+	ServerName string // имя для SNI и проверки сертификата, пустое — хост из Address
+	SkipVerify bool   // не прерывать проверку на недоверенном сертификате
 }
 
 type ServiceStatus struct {
+	LastChecked  time.Time
 	LastSent     time.Time
 	CheckResults []CheckResult
+
+	// неподтверждённое изменение состояния: сколько проверок подряд дали уровень PendingSeverity
+	PendingCount    int
+	PendingSeverity Severity
+
+	// последние подтверждённые уровни для определения флаппинга
+	StateHistory []Severity
+	FlapScore    float64
+	Flapping     bool
+}
+
+// ServiceSnapshot копия состояния сервиса для отображения. Status пустой, пока сервис ни разу не проверялся.
+type ServiceSnapshot struct {
+	Name     string
+	URL      string
+	Address  string
+	Interval time.Duration
+	Checked  bool
+	Status   ServiceStatus
+}
+
+// CheckReport результат одной проверки сервиса: результаты правил и параметры ответа.
+type CheckReport struct {
+	Results    []CheckResult
+	Latency    time.Duration
+	StatusCode int
+	// срок действия сертификата сервера, нулевой если соединение без TLS
+	CertNotAfter time.Time
 }
 
 type ServiceChecker interface {
-	ServiceCheck(ctx context.Context, service *Service) ([]CheckResult, error)
+	ServiceCheck(ctx context.Context, service *Service) (*CheckReport, error)
 }
@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// StateStore хранилище состояний сервисов, чтобы после перезапуска не терять время последнего
+// уведомления и прошлые результаты проверок. Ключ — имя сервиса.
+type StateStore interface {
+	Load(ctx context.Context) (map[string]ServiceStatus, error)
+	Save(ctx context.Context, serviceName string, status ServiceStatus) error
+}
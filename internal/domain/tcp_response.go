@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// NewTCPResponseRule pattern должен быть проверен при загрузке конфига.
+func NewTCPResponseRule(pattern string) CheckRule {
+	return &TCPResponseRule{
+		re: regexp.MustCompile(pattern),
+	}
+}
+
+// TCPResponseRule CRIT, если ответ или баннер сервера не совпал с выражением.
+type TCPResponseRule struct {
+	re *regexp.Regexp
+}
+
+func (c *TCPResponseRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_TCP_RESPONSE
+	logger := slog.With("component", component)
+
+	if c.re.Match(input.Body) {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  fmt.Sprintf("получен ответ %q", truncate(string(input.Body), bodyMatchMaxLen)),
+		}
+	}
+
+	logger.Debug("registered error", "pattern", c.re.String(), "response_size", len(input.Body))
+
+	if len(input.Body) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("сервер ничего не ответил, ожидалось совпадение с %s", c.re),
+		}
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       CRIT,
+		Message:  fmt.Sprintf("ответ %q не совпадает с выражением - %s", truncate(string(input.Body), bodyMatchMaxLen), c.re),
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPResponseRule(t *testing.T) {
+	rule := NewTCPResponseRule(`^\+PONG`)
+
+	t.Run("ответ совпал", func(t *testing.T) {
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("+PONG\r\n")})
+		assert.Equal(t, config.TYPE_TCP_RESPONSE, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+		assert.Equal(t, `получен ответ "+PONG\r\n"`, got.Message)
+	})
+
+	t.Run("ответ не совпал", func(t *testing.T) {
+		got := rule.Check(t.Context(), &CheckInput{Body: []byte("-NOAUTH Authentication required.\r\n")})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, `ответ "-NOAUTH Authentication required.\r\n" не совпадает с выражением - ^\+PONG`, got.Message)
+	})
+
+	t.Run("нет ответа", func(t *testing.T) {
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, `сервер ничего не ответил, ожидалось совпадение с ^\+PONG`, got.Message)
+	})
+}
@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+const (
+	minRSAKeyBits   = 2048
+	minECDSAKeyBits = 256
+)
+
+// TLSCertificatePolicy проверяются только пункты из Severities (ключи config.TLS_FINDING_*).
+// Roots — доверенные корни, nil означает системные.
+type TLSCertificatePolicy struct {
+	Roots      *x509.CertPool
+	Severities map[string]Severity
+}
+
+// NewTLSCertificateRule сертификат получается в отдельном рукопожатии без проверки доверия,
+// поэтому цепочка разбирается по policy.Roots независимо от настроек соединения сервиса.
+func NewTLSCertificateRule(prober TLSProber, target TLSProbeTarget, policy TLSCertificatePolicy) CheckRule {
+	return &TLSCertificateRule{
+		prober: prober,
+		target: target,
+		policy: policy,
+	}
+}
+
+type TLSCertificateRule struct {
+	prober TLSProber
+	target TLSProbeTarget
+	policy TLSCertificatePolicy
+}
+
+type tlsFinding struct {
+	item    string
+	message string
+}
+
+func (c *TLSCertificateRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	component := config.TYPE_TLS_CERTIFICATE
+	logger := slog.With("component", component, "address", c.target.Address)
+
+	state, err := c.prober.Probe(ctx, c.target, 0, nil)
+	if err != nil {
+		logger.Debug("registered error, tls handshake failed", "err", err)
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("не удалось получить сертификат - %s", err),
+		}
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		logger.Debug("server sent no certificates")
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  "сервер не прислал сертификат",
+		}
+	}
+
+	var (
+		messages []string
+		severity = OK
+	)
+
+	for _, finding := range auditCertificates(state.PeerCertificates, c.target.ServerName, c.policy.Roots, time.Now()) {
+		itemSeverity, ok := c.policy.Severities[finding.item]
+		if !ok {
+			continue
+		}
+
+		messages = append(messages, fmt.Sprintf("%s (%s)", finding.message, itemSeverity))
+		severity = max(severity, itemSeverity)
+	}
+
+	if len(messages) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  "сертификат в порядке",
+		}
+	}
+
+	logger.Debug("registered error, certificate findings", "findings", messages)
+
+	return CheckResult{
+		RuleType: component,
+		OK:       severity,
+		Message:  strings.Join(messages, "; "),
+	}
+}
+
+// auditCertificates проверяет цепочку в порядке, в котором её прислал сервер: первым идёт конечный сертификат.
+func auditCertificates(chain []*x509.Certificate, host string, roots *x509.CertPool, now time.Time) []tlsFinding {
+	var findings []tlsFinding
+	add := func(item string, format string, args ...any) {
+		findings = append(findings, tlsFinding{item: item, message: fmt.Sprintf(format, args...)})
+	}
+
+	leaf := chain[0]
+
+	if host != "" {
+		if err := leaf.VerifyHostname(host); err != nil {
+			add(config.TLS_FINDING_HOSTNAME, "сертификат не выдан для %s, имена: %s", host, strings.Join(certificateNames(leaf), ", "))
+		}
+	}
+
+	for _, cert := range chain {
+		if now.Before(cert.NotBefore) {
+			add(config.TLS_FINDING_NOT_YET_VALID, "сертификат %s действует только с %s", certificateName(cert), cert.NotBefore.Format(time.DateOnly))
+		}
+		if now.After(cert.NotAfter) {
+			add(config.TLS_FINDING_EXPIRED, "сертификат %s истёк %s", certificateName(cert), cert.NotAfter.Format(time.DateOnly))
+		}
+	}
+
+	for _, cert := range chain {
+		if weak := weakKey(cert); weak != "" {
+			add(config.TLS_FINDING_WEAK_KEY, "сертификат %s: слабый ключ %s", certificateName(cert), weak)
+		}
+
+		// подпись самоподписанного корня не проверяется клиентом, поэтому не учитывается
+		if !isSelfSigned(cert) && isWeakSignature(cert.SignatureAlgorithm) {
+			add(config.TLS_FINDING_WEAK_SIGNATURE, "сертификат %s подписан %s", certificateName(cert), cert.SignatureAlgorithm)
+		}
+	}
+
+	for _, cert := range chain[1:] {
+		if cert.NotAfter.Before(leaf.NotAfter) && !isSelfSigned(cert) {
+			add(config.TLS_FINDING_INTERMEDIATE_EXPIRY, "промежуточный сертификат %s истекает %s, раньше конечного (%s)",
+				certificateName(cert), cert.NotAfter.Format(time.DateOnly), leaf.NotAfter.Format(time.DateOnly))
+		}
+	}
+
+	if item, message := verifyTrust(chain, roots, now); item != "" {
+		add(item, "%s", message)
+	}
+
+	return findings
+}
+
+// verifyTrust строит цепочку до доверенного корня. Сроки проверяются отдельно, поэтому цепочка
+// проверяется на момент, когда конечный сертификат действителен.
+func verifyTrust(chain []*x509.Certificate, roots *x509.CertPool, now time.Time) (string, string) {
+	leaf := chain[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	at := now
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		at = leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) / 2)
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err == nil {
+		return "", ""
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	if !errors.As(err, &unknownAuthority) {
+		return config.TLS_FINDING_UNTRUSTED_ROOT, fmt.Sprintf("цепочка не прошла проверку - %s", err)
+	}
+
+	if len(chain) == 1 && isSelfSigned(leaf) {
+		return config.TLS_FINDING_SELF_SIGNED, fmt.Sprintf("сертификат %s самоподписанный", certificateName(leaf))
+	}
+
+	last := chain[len(chain)-1]
+	if isSelfSigned(last) {
+		return config.TLS_FINDING_UNTRUSTED_ROOT, fmt.Sprintf("цепочка заканчивается недоверенным корнем %s", certificateName(last))
+	}
+
+	return config.TLS_FINDING_INCOMPLETE_CHAIN, fmt.Sprintf("неполная цепочка или недоверенный корень: нет издателя %s", last.Issuer.CommonName)
+}
+
+// isSelfSigned CheckSignatureFrom не подходит: он требует, чтобы издатель был CA, а самоподписанный конечный сертификат им не является.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func weakKey(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < minRSAKeyBits {
+			return fmt.Sprintf("RSA %d бит", bits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := key.Curve.Params().BitSize; bits < minECDSAKeyBits {
+			return fmt.Sprintf("ECDSA %d бит", bits)
+		}
+	}
+
+	return ""
+}
+
+func isWeakSignature(algorithm x509.SignatureAlgorithm) bool {
+	switch algorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		return true
+	}
+
+	return false
+}
+
+func certificateName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+
+	return cert.Subject.String()
+}
+
+func certificateNames(cert *x509.Certificate) []string {
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames
+	}
+
+	return []string{cert.Subject.CommonName}
+}
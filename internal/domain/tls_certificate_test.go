@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+type testCertificateOptions struct {
+	commonName string
+	dnsNames   []string
+	isCA       bool
+	notBefore  time.Time
+	notAfter   time.Time
+	key        crypto.Signer
+	signature  x509.SignatureAlgorithm
+}
+
+// issueCertificate выпускает сертификат, подписанный parent, или самоподписанный, если parent nil.
+func issueCertificate(t *testing.T, parent *testCertificate, options testCertificateOptions) *testCertificate {
+	t.Helper()
+
+	key := options.key
+	if key == nil {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+	}
+
+	if options.notBefore.IsZero() {
+		options.notBefore = time.Now().Add(-time.Hour)
+	}
+	if options.notAfter.IsZero() {
+		options.notAfter = time.Now().Add(90 * 24 * time.Hour)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: options.commonName},
+		DNSNames:              options.dnsNames,
+		NotBefore:             options.notBefore,
+		NotAfter:              options.notAfter,
+		IsCA:                  options.isCA,
+		BasicConstraintsValid: true,
+		SignatureAlgorithm:    options.signature,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+// certificateProber сервер отдаёт цепочку chain или ошибку err.
+type certificateProber struct {
+	chain []*x509.Certificate
+	err   error
+}
+
+func (p *certificateProber) Probe(ctx context.Context, target TLSProbeTarget, version uint16, cipherSuites []uint16) (*tls.ConnectionState, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return &tls.ConnectionState{PeerCertificates: p.chain}, nil
+}
+
+func TestTLSCertificateRule(t *testing.T) {
+	root := issueCertificate(t, nil, testCertificateOptions{commonName: "Test Root", isCA: true, notAfter: time.Now().Add(10 * 365 * 24 * time.Hour)})
+	intermediate := issueCertificate(t, root, testCertificateOptions{commonName: "Test CA", isCA: true, notAfter: time.Now().Add(365 * 24 * time.Hour)})
+	leaf := issueCertificate(t, intermediate, testCertificateOptions{commonName: "example.ru", dnsNames: []string{"example.ru", "www.example.ru"}})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+
+	severities := map[string]Severity{}
+	for item, severity := range config.TLSCertificateFindings {
+		severities[item] = map[string]Severity{config.SEVERITY_WARN: WARN, config.SEVERITY_CRIT: CRIT}[severity]
+	}
+	policy := TLSCertificatePolicy{Roots: roots, Severities: severities}
+
+	check := func(host string, chain ...*testCertificate) CheckResult {
+		prober := &certificateProber{}
+		for _, cert := range chain {
+			prober.chain = append(prober.chain, cert.cert)
+		}
+		rule := NewTLSCertificateRule(prober, TLSProbeTarget{Address: host + ":443", ServerName: host}, policy)
+		return rule.Check(t.Context(), &CheckInput{})
+	}
+
+	t.Run("корректная цепочка", func(t *testing.T) {
+		got := check("www.example.ru", leaf, intermediate)
+		assert.Equal(t, config.TYPE_TLS_CERTIFICATE, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("сертификат для другого имени", func(t *testing.T) {
+		got := check("shop.example.ru", leaf, intermediate)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "сертификат не выдан для shop.example.ru, имена: example.ru, www.example.ru (crit)", got.Message)
+	})
+
+	t.Run("сервер не прислал промежуточный сертификат", func(t *testing.T) {
+		got := check("example.ru", leaf)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "неполная цепочка или недоверенный корень: нет издателя Test CA (crit)", got.Message)
+	})
+
+	t.Run("недоверенный корень", func(t *testing.T) {
+		otherRoot := issueCertificate(t, nil, testCertificateOptions{commonName: "Other Root", isCA: true})
+		otherIntermediate := issueCertificate(t, otherRoot, testCertificateOptions{commonName: "Other CA", isCA: true})
+		otherLeaf := issueCertificate(t, otherIntermediate, testCertificateOptions{commonName: "example.ru", dnsNames: []string{"example.ru"}})
+
+		got := check("example.ru", otherLeaf, otherIntermediate, otherRoot)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "цепочка заканчивается недоверенным корнем Other Root (crit)", got.Message)
+	})
+
+	t.Run("самоподписанный сертификат", func(t *testing.T) {
+		selfSigned := issueCertificate(t, nil, testCertificateOptions{commonName: "example.ru", dnsNames: []string{"example.ru"}})
+
+		got := check("example.ru", selfSigned)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "сертификат example.ru самоподписанный (crit)", got.Message)
+	})
+
+	t.Run("сертификат ещё не действует", func(t *testing.T) {
+		notBefore := time.Now().Add(48 * time.Hour)
+		future := issueCertificate(t, intermediate, testCertificateOptions{commonName: "example.ru", dnsNames: []string{"example.ru"}, notBefore: notBefore})
+
+		got := check("example.ru", future, intermediate)
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "сертификат example.ru действует только с "+notBefore.Format(time.DateOnly)+" (crit)", got.Message)
+	})
+
+	t.Run("слабый ключ и промежуточный истекает раньше конечного", func(t *testing.T) {
+		weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+
+		notAfter := time.Now().Add(2 * 365 * 24 * time.Hour)
+		weak := issueCertificate(t, intermediate, testCertificateOptions{commonName: "example.ru", dnsNames: []string{"example.ru"}, key: weakKey, notAfter: notAfter})
+
+		got := check("example.ru", weak, intermediate)
+		assert.Equal(t, Severity(WARN), got.OK)
+		assert.Equal(t, "сертификат example.ru: слабый ключ RSA 1024 бит (warn); "+
+			"промежуточный сертификат Test CA истекает "+intermediate.cert.NotAfter.Format(time.DateOnly)+
+			", раньше конечного ("+notAfter.Format(time.DateOnly)+") (warn)", got.Message)
+	})
+
+	t.Run("отключённые пункты не проверяются", func(t *testing.T) {
+		prober := &certificateProber{chain: []*x509.Certificate{leaf.cert}}
+		rule := NewTLSCertificateRule(prober, TLSProbeTarget{Address: "other.ru:443", ServerName: "other.ru"}, TLSCertificatePolicy{Roots: roots, Severities: map[string]Severity{config.TLS_FINDING_EXPIRED: CRIT}})
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(OK), got.OK)
+	})
+
+	t.Run("рукопожатие не удалось", func(t *testing.T) {
+		prober := &certificateProber{err: errors.New("failed connect: connection refused")}
+		rule := NewTLSCertificateRule(prober, TLSProbeTarget{Address: "example.ru:443", ServerName: "example.ru"}, policy)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "не удалось получить сертификат - failed connect: connection refused", got.Message)
+	})
+}
+
+func TestIsWeakSignature(t *testing.T) {
+	assert.True(t, isWeakSignature(x509.SHA1WithRSA))
+	assert.True(t, isWeakSignature(x509.ECDSAWithSHA1))
+	assert.False(t, isWeakSignature(x509.SHA256WithRSA))
+	assert.False(t, isWeakSignature(x509.ECDSAWithSHA256))
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// TLSProbeTarget куда подключается TLSProber: для http-сервиса — хост и порт из url.
+type TLSProbeTarget struct {
+	Address    string
+	ServerName string
+	StartTLS   string
+}
+
+// TLSProber выполняет отдельное рукопожатие без проверки доверия к сертификату, в котором клиент
+// предлагает только версию version (0 — версии по умолчанию) и шифры cipherSuites (пустой список — все
+// поддерживаемые). Ошибка означает, что сервер не согласился.
+type TLSProber interface {
+	Probe(ctx context.Context, target TLSProbeTarget, version uint16, cipherSuites []uint16) (*tls.ConnectionState, error)
+}
+
+var tlsProbeVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// NewTLSPolicyRule версии ниже minVersion считаются небезопасными. Проверяются только пункты
+// из severities (ключи config.TLS_POLICY_*). Результат проб переиспользуется probeInterval,
+// политика сервера меняется редко, а проб на каждую проверку до семи.
+func NewTLSPolicyRule(prober TLSProber, target TLSProbeTarget, minVersion uint16, probeInterval time.Duration, severities map[string]Severity) CheckRule {
+	return &TLSPolicyRule{
+		prober:        prober,
+		target:        target,
+		minVersion:    minVersion,
+		probeInterval: probeInterval,
+		severities:    severities,
+	}
+}
+
+type TLSPolicyRule struct {
+	prober        TLSProber
+	target        TLSProbeTarget
+	minVersion    uint16
+	probeInterval time.Duration
+	severities    map[string]Severity
+
+	mu       sync.Mutex
+	cached   *CheckResult
+	probedAt time.Time
+}
+
+// tlsVersionProbe результат проб одной версии: state nil, если версия не принята.
+type tlsVersionProbe struct {
+	state *tls.ConnectionState
+	err   error
+	weak  *tls.ConnectionState
+}
+
+func (c *TLSPolicyRule) Check(ctx context.Context, input *CheckInput) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.probedAt) < c.probeInterval {
+		return *c.cached
+	}
+
+	result, reachable := c.probe(ctx)
+
+	// недоступность сервера не кэшируется, чтобы восстановление было видно на следующей проверке
+	if reachable {
+		c.cached = &result
+		c.probedAt = time.Now()
+	}
+
+	return result
+}
+
+// probe проверяет все версии параллельно, второе значение — принял ли сервер хотя бы одну.
+func (c *TLSPolicyRule) probe(ctx context.Context) (CheckResult, bool) {
+	component := config.TYPE_TLS_POLICY
+	logger := slog.With("component", component, "address", c.target.Address)
+
+	probes := make([]tlsVersionProbe, len(tlsProbeVersions))
+
+	var wg sync.WaitGroup
+	for idx, version := range tlsProbeVersions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probes[idx] = c.probeVersion(ctx, version)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		accepted   []string
+		negotiated *tls.ConnectionState
+		lastErr    error
+		findings   []string
+		severity   = OK
+	)
+
+	addFinding := func(item string, message string) {
+		itemSeverity, ok := c.severities[item]
+		if !ok {
+			return
+		}
+
+		findings = append(findings, fmt.Sprintf("%s (%s)", message, itemSeverity))
+		severity = max(severity, itemSeverity)
+	}
+
+	for idx, version := range tlsProbeVersions {
+		probe := probes[idx]
+		if probe.state == nil {
+			logger.Debug("tls version rejected", "version", tls.VersionName(version), "err", probe.err)
+			lastErr = probe.err
+			continue
+		}
+
+		accepted = append(accepted, tls.VersionName(version))
+		negotiated = probe.state
+
+		if version < c.minVersion {
+			addFinding(config.TLS_POLICY_INSECURE_VERSION, fmt.Sprintf("принимается %s", tls.VersionName(version)))
+		}
+
+		if probe.weak != nil {
+			addFinding(config.TLS_POLICY_WEAK_CIPHER, fmt.Sprintf("принимается слабый шифр %s (%s)", tls.CipherSuiteName(probe.weak.CipherSuite), tls.VersionName(version)))
+		}
+	}
+
+	if negotiated == nil {
+		return CheckResult{
+			RuleType: component,
+			OK:       CRIT,
+			Message:  fmt.Sprintf("сервер не принял ни одну версию TLS - %s", lastErr),
+		}, false
+	}
+
+	summary := fmt.Sprintf("принимаются %s, шифр %s", strings.Join(accepted, ", "), tls.CipherSuiteName(negotiated.CipherSuite))
+	if len(findings) == 0 {
+		return CheckResult{
+			RuleType: component,
+			OK:       OK,
+			Message:  summary,
+		}, true
+	}
+
+	return CheckResult{
+		RuleType: component,
+		OK:       severity,
+		Message:  strings.Join(findings, "; ") + "; " + summary,
+	}, true
+}
+
+// probeVersion рукопожатие с версией version и, если она принята, со слабыми шифрами.
+func (c *TLSPolicyRule) probeVersion(ctx context.Context, version uint16) tlsVersionProbe {
+	state, err := c.prober.Probe(ctx, c.target, version, allCipherSuites(version))
+	if err != nil {
+		return tlsVersionProbe{err: err}
+	}
+
+	// в TLS 1.3 набор шифров не настраивается, слабых в нём нет
+	if version == tls.VersionTLS13 {
+		return tlsVersionProbe{state: state}
+	}
+
+	weak, err := c.prober.Probe(ctx, c.target, version, weakCipherSuites(version))
+	if err != nil {
+		return tlsVersionProbe{state: state}
+	}
+
+	return tlsVersionProbe{state: state, weak: weak}
+}
+
+// allCipherSuites все шифры версии, включая небезопасные, чтобы сервер мог выбрать любой.
+// Для TLS 1.3 шифры не задаются.
+func allCipherSuites(version uint16) []uint16 {
+	if version == tls.VersionTLS13 {
+		return nil
+	}
+
+	var result []uint16
+	for _, suite := range slices.Concat(tls.CipherSuites(), tls.InsecureCipherSuites()) {
+		if slices.Contains(suite.SupportedVersions, version) {
+			result = append(result, suite.ID)
+		}
+	}
+
+	return result
+}
+
+func weakCipherSuites(version uint16) []uint16 {
+	var result []uint16
+	for _, suite := range tls.InsecureCipherSuites() {
+		if slices.Contains(suite.SupportedVersions, version) {
+			result = append(result, suite.ID)
+		}
+	}
+
+	return result
+}
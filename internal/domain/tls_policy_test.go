@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTLSProber сервер принимает версии из versions, а слабые шифры — если weakCipher не ноль.
+type fakeTLSProber struct {
+	versions   []uint16
+	weakCipher uint16
+	probes     atomic.Int32
+	down       bool
+}
+
+func (p *fakeTLSProber) Probe(ctx context.Context, target TLSProbeTarget, version uint16, cipherSuites []uint16) (*tls.ConnectionState, error) {
+	p.probes.Add(1)
+	if p.down {
+		return nil, errors.New("failed connect: connection refused")
+	}
+
+	if !slices.Contains(p.versions, version) {
+		return nil, errors.New("remote error: tls: protocol version not supported")
+	}
+
+	if version == tls.VersionTLS13 {
+		return &tls.ConnectionState{Version: version, CipherSuite: tls.TLS_AES_128_GCM_SHA256}, nil
+	}
+
+	if slices.Contains(cipherSuites, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) {
+		return &tls.ConnectionState{Version: version, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, nil
+	}
+
+	if p.weakCipher != 0 && slices.Contains(cipherSuites, p.weakCipher) {
+		return &tls.ConnectionState{Version: version, CipherSuite: p.weakCipher}, nil
+	}
+
+	return nil, errors.New("remote error: tls: handshake failure")
+}
+
+func TestTLSPolicyRule(t *testing.T) {
+	severities := map[string]Severity{
+		config.TLS_POLICY_INSECURE_VERSION: CRIT,
+		config.TLS_POLICY_WEAK_CIPHER:      WARN,
+	}
+
+	t.Run("только современные версии", func(t *testing.T) {
+		prober := &fakeTLSProber{versions: []uint16{tls.VersionTLS12, tls.VersionTLS13}}
+		rule := NewTLSPolicyRule(prober, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, 0, severities)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, config.TYPE_TLS_POLICY, got.RuleType)
+		assert.Equal(t, Severity(OK), got.OK)
+		assert.Equal(t, "принимаются TLS 1.2, TLS 1.3, шифр TLS_AES_128_GCM_SHA256", got.Message)
+	})
+
+	t.Run("небезопасная версия и слабый шифр", func(t *testing.T) {
+		prober := &fakeTLSProber{versions: []uint16{tls.VersionTLS10, tls.VersionTLS12}, weakCipher: tls.TLS_RSA_WITH_RC4_128_SHA}
+		rule := NewTLSPolicyRule(prober, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, 0, severities)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "принимается TLS 1.0 (crit); "+
+			"принимается слабый шифр TLS_RSA_WITH_RC4_128_SHA (TLS 1.0) (warn); "+
+			"принимается слабый шифр TLS_RSA_WITH_RC4_128_SHA (TLS 1.2) (warn); "+
+			"принимаются TLS 1.0, TLS 1.2, шифр TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", got.Message)
+	})
+
+	t.Run("отключённый пункт", func(t *testing.T) {
+		prober := &fakeTLSProber{versions: []uint16{tls.VersionTLS11, tls.VersionTLS12}}
+		rule := NewTLSPolicyRule(prober, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, 0, map[string]Severity{})
+
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), &CheckInput{}).OK)
+	})
+
+	t.Run("ни одна версия не принята", func(t *testing.T) {
+		rule := NewTLSPolicyRule(&fakeTLSProber{}, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, 0, severities)
+
+		got := rule.Check(t.Context(), &CheckInput{})
+		assert.Equal(t, Severity(CRIT), got.OK)
+		assert.Equal(t, "сервер не принял ни одну версию TLS - remote error: tls: protocol version not supported", got.Message)
+	})
+
+	t.Run("результат переиспользуется до probe_interval", func(t *testing.T) {
+		prober := &fakeTLSProber{versions: []uint16{tls.VersionTLS12, tls.VersionTLS13}}
+		rule := NewTLSPolicyRule(prober, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, time.Hour, severities)
+
+		first := rule.Check(t.Context(), &CheckInput{})
+		probes := prober.probes.Load()
+		assert.Equal(t, int32(5), probes)
+
+		assert.Equal(t, first, rule.Check(t.Context(), &CheckInput{}))
+		assert.Equal(t, probes, prober.probes.Load())
+	})
+
+	t.Run("недоступность сервера не кэшируется", func(t *testing.T) {
+		prober := &fakeTLSProber{versions: []uint16{tls.VersionTLS12}, down: true}
+		rule := NewTLSPolicyRule(prober, TLSProbeTarget{Address: "example.ru:443"}, tls.VersionTLS12, time.Hour, severities)
+
+		assert.Equal(t, Severity(CRIT), rule.Check(t.Context(), &CheckInput{}).OK)
+
+		prober.down = false
+		assert.Equal(t, Severity(OK), rule.Check(t.Context(), &CheckInput{}).OK)
+	})
+}
@@ -0,0 +1,152 @@
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/miekg/dns"
+)
+
+// NewChecker проверяет сервисы типа dns: запрос записи к резолверам по очереди до первого ответа.
+func NewChecker(timeout time.Duration) domain.ServiceChecker {
+	return &DNSServiceChecker{
+		timeout: timeout,
+	}
+}
+
+type DNSServiceChecker struct {
+	timeout time.Duration
+}
+
+func (c *DNSServiceChecker) ServiceCheck(ctx context.Context, service *domain.Service) (*domain.CheckReport, error) {
+	logger := slog.With("component", "dnsservicechecker", "service_name", service.Name, "host", service.DNS.Host, "record_type", service.DNS.RecordType)
+
+	logger.Debug("starts service check")
+
+	// таймаут только на запрос, dns_consistency опрашивает резолверы со своим таймаутом
+	queryCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if len(service.DNS.Resolvers) == 0 {
+		return nil, errors.New("no resolvers")
+	}
+
+	var lastErr error
+	for _, resolver := range service.DNS.Resolvers {
+		records, latency, err := Query(queryCtx, resolver, service.DNS.Host, service.DNS.RecordType)
+		if err != nil {
+			logger.Warn("failed dns query", "resolver", resolver, "err", err)
+			lastErr = err
+			continue
+		}
+
+		logger.Debug("got dns answer", "resolver", resolver, "records", len(records), "latency", latency)
+		cancel()
+
+		checkInput := &domain.CheckInput{
+			Latency: latency,
+			DNS:     records,
+		}
+
+		var result []domain.CheckResult
+		for _, rule := range service.Rules {
+			result = append(result, rule.Check(ctx, checkInput))
+		}
+
+		return &domain.CheckReport{
+			Results: result,
+			Latency: latency,
+		}, nil
+	}
+
+	return nil, lastErr
+}
+
+// NewResolver запросы к отдельным резолверам для проверки dns_consistency.
+func NewResolver(timeout time.Duration) domain.DNSResolver {
+	return &Resolver{
+		timeout: timeout,
+	}
+}
+
+type Resolver struct {
+	timeout time.Duration
+}
+
+func (r *Resolver) Query(ctx context.Context, resolver string, host string, recordType string) ([]domain.DNSRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	records, _, err := Query(ctx, resolver, host, recordType)
+
+	return records, err
+}
+
+// Query запрашивает у resolver записи recordType для host, усечённый UDP-ответ повторяется по TCP.
+// Ответ с кодом, отличным от NOERROR, считается ошибкой.
+func Query(ctx context.Context, resolver string, host string, recordType string) ([]domain.DNSRecord, time.Duration, error) {
+	qtype, ok := dns.StringToType[recordType]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown record type %s", recordType)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), qtype)
+
+	client := &dns.Client{Net: "udp"}
+	answer, latency, err := client.ExchangeContext(ctx, msg, resolver)
+	if err == nil && answer.Truncated {
+		client.Net = "tcp"
+		answer, latency, err = client.ExchangeContext(ctx, msg, resolver)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("resolver %s: %w", resolver, err)
+	}
+
+	if answer.Rcode != dns.RcodeSuccess {
+		return nil, latency, fmt.Errorf("resolver %s answered %s", resolver, dns.RcodeToString[answer.Rcode])
+	}
+
+	var records []domain.DNSRecord
+	for _, rr := range answer.Answer {
+		// в ответе на A может прийти цепочка CNAME, нужны только записи запрошенного типа
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+
+		records = append(records, domain.DNSRecord{
+			Value: config.NormalizeDNSValue(recordType, recordValue(rr)),
+			TTL:   time.Duration(rr.Header().Ttl) * time.Second,
+		})
+	}
+
+	return records, latency, nil
+}
+
+// recordValue значение записи в формате config.NormalizeDNSValue.
+func recordValue(rr dns.RR) string {
+	switch record := rr.(type) {
+	case *dns.A:
+		return record.A.String()
+	case *dns.AAAA:
+		return record.AAAA.String()
+	case *dns.CNAME:
+		return record.Target
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", record.Preference, record.Mx)
+	case *dns.TXT:
+		return strings.Join(record.Txt, "")
+	case *dns.NS:
+		return record.Ns
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %q", record.Flag, record.Tag, record.Value)
+	}
+
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
@@ -0,0 +1,218 @@
+package dnscheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveDNS запускает резолвер на UDP и TCP одного порта, handler отвечает на все запросы.
+func serveDNS(t *testing.T, handler dns.HandlerFunc) string {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", packetConn.LocalAddr().String())
+	require.NoError(t, err)
+
+	for _, server := range []*dns.Server{
+		{PacketConn: packetConn, Handler: handler},
+		{Listener: listener, Handler: handler},
+	} {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go server.ActivateAndServe()
+		<-started
+		t.Cleanup(func() { server.Shutdown() })
+	}
+
+	return packetConn.LocalAddr().String()
+}
+
+// answerWith отвечает записями zone, у которых совпадает тип с вопросом или которые являются CNAME.
+func answerWith(t *testing.T, zone ...string) dns.HandlerFunc {
+	var records []dns.RR
+	for _, line := range zone {
+		rr, err := dns.NewRR(line)
+		require.NoError(t, err)
+		records = append(records, rr)
+	}
+
+	return func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		for _, rr := range records {
+			if rr.Header().Rrtype == req.Question[0].Qtype || rr.Header().Rrtype == dns.TypeCNAME {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+		w.WriteMsg(resp)
+	}
+}
+
+// deadlineRule запоминает, ограничен ли контекст, с которым вызвано правило.
+type deadlineRule struct {
+	hasDeadline bool
+}
+
+func (r *deadlineRule) Check(ctx context.Context, input *domain.CheckInput) domain.CheckResult {
+	_, r.hasDeadline = ctx.Deadline()
+	return domain.CheckResult{OK: domain.OK}
+}
+
+func TestDNSServiceChecker(t *testing.T) {
+	checker := NewChecker(time.Second)
+
+	t.Run("таймаут запроса не распространяется на правила", func(t *testing.T) {
+		resolver := serveDNS(t, answerWith(t, "example.ru. 300 IN A 192.0.2.1"))
+
+		rule := &deadlineRule{}
+		_, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			DNS:   domain.DNSQuery{Host: "example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{resolver}},
+			Rules: []domain.CheckRule{rule},
+		})
+		require.NoError(t, err)
+		assert.False(t, rule.hasDeadline)
+	})
+
+	t.Run("значения, количество и TTL", func(t *testing.T) {
+		resolver := serveDNS(t, answerWith(t,
+			"example.ru. 300 IN A 192.0.2.1",
+			"example.ru. 300 IN A 192.0.2.2",
+		))
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Type: config.SERVICE_TYPE_DNS,
+			DNS:  domain.DNSQuery{Host: "example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{resolver}},
+			Rules: []domain.CheckRule{
+				domain.NewDNSValuesRule([]string{"192.0.2.1", "192.0.2.2"}, true),
+				domain.NewDNSCountRule(2),
+				domain.NewDNSTTLRule(time.Minute, time.Hour, domain.WARN),
+			},
+		})
+		require.NoError(t, err)
+
+		for _, result := range report.Results {
+			assert.Equal(t, domain.OK, result.OK, result.RuleType+": "+result.Message)
+		}
+		assert.Equal(t, "192.0.2.1, 192.0.2.2", report.Results[0].Message)
+	})
+
+	t.Run("запись изменена", func(t *testing.T) {
+		resolver := serveDNS(t, answerWith(t, "example.ru. 300 IN A 198.51.100.7"))
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			DNS:   domain.DNSQuery{Host: "example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{resolver}},
+			Rules: []domain.CheckRule{domain.NewDNSValuesRule([]string{"192.0.2.1"}, false)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.CRIT, report.Results[0].OK)
+		assert.Equal(t, "нет значений 192.0.2.1; получено: 198.51.100.7", report.Results[0].Message)
+	})
+
+	t.Run("цепочка CNAME не попадает в ответ на A", func(t *testing.T) {
+		resolver := serveDNS(t, answerWith(t,
+			"www.example.ru. 300 IN CNAME example.ru.",
+			"example.ru. 300 IN A 192.0.2.1",
+		))
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			DNS:   domain.DNSQuery{Host: "www.example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{resolver}},
+			Rules: []domain.CheckRule{domain.NewDNSValuesRule([]string{"192.0.2.1"}, true)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.OK, report.Results[0].OK, report.Results[0].Message)
+	})
+
+	t.Run("формат значений по типам", func(t *testing.T) {
+		resolver := serveDNS(t, answerWith(t,
+			"example.ru. 300 IN MX 10 MX.Example.ru.",
+			`example.ru. 300 IN TXT "v=spf1 " "-all"`,
+			"example.ru. 300 IN NS ns1.example.ru.",
+			`example.ru. 300 IN CAA 0 issue "letsencrypt.org"`,
+			"example.ru. 300 IN AAAA 2001:db8:0::1",
+		))
+
+		cases := map[string]string{
+			config.DNS_RECORD_MX:   "10 mx.example.ru",
+			config.DNS_RECORD_TXT:  "v=spf1 -all",
+			config.DNS_RECORD_NS:   "ns1.example.ru",
+			config.DNS_RECORD_CAA:  `0 issue "letsencrypt.org"`,
+			config.DNS_RECORD_AAAA: "2001:db8::1",
+		}
+		for recordType, expected := range cases {
+			records, _, err := Query(t.Context(), resolver, "example.ru", recordType)
+			require.NoError(t, err)
+			require.Len(t, records, 1, recordType)
+			assert.Equal(t, expected, records[0].Value, recordType)
+			assert.Equal(t, 300*time.Second, records[0].TTL)
+		}
+	})
+
+	t.Run("усечённый ответ повторяется по TCP", func(t *testing.T) {
+		full := answerWith(t, "example.ru. 300 IN A 192.0.2.1")
+		resolver := serveDNS(t, func(w dns.ResponseWriter, req *dns.Msg) {
+			if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+				resp := new(dns.Msg)
+				resp.SetReply(req)
+				resp.Truncated = true
+				w.WriteMsg(resp)
+				return
+			}
+			full(w, req)
+		})
+
+		records, _, err := Query(t.Context(), resolver, "example.ru", config.DNS_RECORD_A)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.DNSRecord{{Value: "192.0.2.1", TTL: 300 * time.Second}}, records)
+	})
+
+	t.Run("переход к следующему резолверу", func(t *testing.T) {
+		failing := serveDNS(t, func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetRcode(req, dns.RcodeServerFailure)
+			w.WriteMsg(resp)
+		})
+		resolver := serveDNS(t, answerWith(t, "example.ru. 300 IN A 192.0.2.1"))
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			DNS:   domain.DNSQuery{Host: "example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{failing, resolver}},
+			Rules: []domain.CheckRule{domain.NewDNSCountRule(1)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.OK, report.Results[0].OK)
+	})
+
+	t.Run("NXDOMAIN", func(t *testing.T) {
+		resolver := serveDNS(t, func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetRcode(req, dns.RcodeNameError)
+			w.WriteMsg(resp)
+		})
+
+		_, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			DNS: domain.DNSQuery{Host: "example.ru", RecordType: config.DNS_RECORD_A, Resolvers: []string{resolver}},
+		})
+		assert.ErrorContains(t, err, "answered NXDOMAIN")
+	})
+}
+
+func TestResolver(t *testing.T) {
+	internal := serveDNS(t, answerWith(t, "example.ru. 300 IN A 10.1.1.1"))
+	public := serveDNS(t, answerWith(t, "example.ru. 60 IN A 192.0.2.1"))
+	public2 := serveDNS(t, answerWith(t, "example.ru. 3600 IN A 192.0.2.1"))
+
+	rule := domain.NewDNSConsistencyRule(NewResolver(time.Second), "example.ru", config.DNS_RECORD_A, []string{internal, public, public2})
+
+	got := rule.Check(t.Context(), &domain.CheckInput{})
+	assert.Equal(t, domain.WARN, got.OK)
+	assert.Equal(t, "ответы резолверов расходятся: "+internal+" - 10.1.1.1; "+public+", "+public2+" - 192.0.2.1", got.Message)
+}
@@ -11,17 +11,20 @@ import (
 	"github.com/kias-hack/web-watcher/internal/domain"
 )
 
-func NewChecker(client *http.Client) domain.ServiceChecker {
+// NewChecker skipVerifyClient используется для сервисов с tls_skip_verify.
+func NewChecker(client *http.Client, skipVerifyClient *http.Client) domain.ServiceChecker {
 	return &HTTPServiceChecker{
-		httpClient: client,
+		httpClient:       client,
+		skipVerifyClient: skipVerifyClient,
 	}
 }
 
 type HTTPServiceChecker struct {
-	httpClient *http.Client
+	httpClient       *http.Client
+	skipVerifyClient *http.Client
 }
 
-func (c *HTTPServiceChecker) ServiceCheck(ctx context.Context, service *domain.Service) ([]domain.CheckResult, error) {
+func (c *HTTPServiceChecker) ServiceCheck(ctx context.Context, service *domain.Service) (*domain.CheckReport, error) {
 	logger := slog.With("component", "httpservicechecker", "service_name", service.Name, "url", service.URL)
 
 	logger.Debug("starts service check")
@@ -31,8 +34,13 @@ func (c *HTTPServiceChecker) ServiceCheck(ctx context.Context, service *domain.S
 		return nil, fmt.Errorf("failed create request: %w", err)
 	}
 
+	client := c.httpClient
+	if service.TLS.SkipVerify {
+		client = c.skipVerifyClient
+	}
+
 	start := time.Now()
-	resp, err := c.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed request: %w", err)
 	}
@@ -46,10 +54,12 @@ func (c *HTTPServiceChecker) ServiceCheck(ctx context.Context, service *domain.S
 
 	logger.Debug("got service response", "status_code", resp.StatusCode, "latency", latency)
 
+	// после редиректов сертификат относится к последнему запросу
 	checkInput := &domain.CheckInput{
 		Response: resp,
 		Latency:  latency,
 		Body:     bodyBytes,
+		TLS:      resp.TLS,
 	}
 
 	logger.Debug("runs checks")
@@ -59,5 +69,14 @@ func (c *HTTPServiceChecker) ServiceCheck(ctx context.Context, service *domain.S
 		result = append(result, rule.Check(ctx, checkInput))
 	}
 
-	return result, nil
+	report := &domain.CheckReport{
+		Results:    result,
+		Latency:    latency,
+		StatusCode: resp.StatusCode,
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		report.CertNotAfter = resp.TLS.PeerCertificates[0].NotAfter
+	}
+
+	return report, nil
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kias-hack/web-watcher/internal/config"
 	"github.com/kias-hack/web-watcher/internal/domain"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,17 +23,19 @@ func TestHTTPServiceChecker(t *testing.T) {
 		httpClient: http.DefaultClient,
 	}
 
-	result, err := checker.ServiceCheck(t.Context(), &domain.Service{
+	report, err := checker.ServiceCheck(t.Context(), &domain.Service{
 		URL: server.URL,
 		Rules: []domain.CheckRule{
-			domain.NewStatusCodeRule(http.StatusOK),
+			domain.NewStatusCodeRule([]config.StatusCodeRange{{From: http.StatusOK, To: http.StatusOK}}, nil),
 			domain.NewLatencyRule(150),
-			domain.NewBodyMatchRule("<title>Test</title>"),
+			domain.NewBodyMatchRule([]string{"<title>Test</title>"}, false),
 		},
 	})
 
 	assert.NoError(t, err)
-	for _, res := range result {
+	assert.Equal(t, http.StatusOK, report.StatusCode)
+	assert.GreaterOrEqual(t, report.Latency, 100*time.Millisecond)
+	for _, res := range report.Results {
 		t.Log(res.RuleType, res.Message)
 		assert.Equal(t, domain.OK, res.OK)
 	}
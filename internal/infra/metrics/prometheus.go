@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const METRICS_NAMESPACE = "webwatcher"
+
+// Prometheus собирает метрики проверок и доставки уведомлений в собственный реестр.
+// Реализует watchdog.Metrics и delivery.Metrics.
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	serviceUp       *prometheus.GaugeVec
+	serviceSeverity *prometheus.GaugeVec
+	ruleSeverity    *prometheus.GaugeVec
+	checkDuration   *prometheus.HistogramVec
+	statusCode      *prometheus.GaugeVec
+	sslDaysLeft     *prometheus.GaugeVec
+	checkErrors     *prometheus.CounterVec
+
+	notificationsSent    *prometheus.CounterVec
+	notificationsFailed  *prometheus.CounterVec
+	notificationsDropped *prometheus.CounterVec
+	queueDepth           *prometheus.GaugeVec
+}
+
+func NewPrometheus() *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+
+		serviceUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "service_up",
+			Help:      "1 if the last check got a response from the service, 0 otherwise.",
+		}, []string{"service"}),
+		serviceSeverity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "service_severity",
+			Help:      "Confirmed service severity: 0 ok, 1 warn, 2 crit.",
+		}, []string{"service"}),
+		ruleSeverity: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "rule_severity",
+			Help:      "Severity of the last check per rule type: 0 ok, 1 warn, 2 crit.",
+		}, []string{"service", "rule_type"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "check_duration_seconds",
+			Help:      "Service response latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service"}),
+		statusCode: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "http_status_code",
+			Help:      "HTTP status code of the last check, 0 if the request failed.",
+		}, []string{"service"}),
+		sslDaysLeft: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "ssl_days_until_expiry",
+			Help:      "Days until the server certificate expires.",
+		}, []string{"service"}),
+		checkErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "check_errors_total",
+			Help:      "Checks that failed to get a response from the service.",
+		}, []string{"service"}),
+
+		notificationsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "notifications_sent_total",
+			Help:      "Successful notification send attempts.",
+		}, []string{"notifier"}),
+		notificationsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "notifications_failed_total",
+			Help:      "Failed notification send attempts.",
+		}, []string{"notifier"}),
+		notificationsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "notifications_dropped_total",
+			Help:      "Notifications moved to the outbox because the notifier queue was full.",
+		}, []string{"notifier"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: METRICS_NAMESPACE,
+			Name:      "notification_queue_depth",
+			Help:      "Events waiting in the notifier queue.",
+		}, []string{"notifier"}),
+	}
+
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		p.serviceUp,
+		p.serviceSeverity,
+		p.ruleSeverity,
+		p.checkDuration,
+		p.statusCode,
+		p.sslDaysLeft,
+		p.checkErrors,
+		p.notificationsSent,
+		p.notificationsFailed,
+		p.notificationsDropped,
+		p.queueDepth,
+	)
+
+	return p
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *Prometheus) CheckCompleted(serviceName string, report *domain.CheckReport, err error) {
+	if err != nil {
+		p.serviceUp.WithLabelValues(serviceName).Set(0)
+		p.statusCode.WithLabelValues(serviceName).Set(0)
+		p.checkErrors.WithLabelValues(serviceName).Inc()
+		return
+	}
+
+	p.serviceUp.WithLabelValues(serviceName).Set(1)
+	// у сервисов без http кода ответа нет
+	if report.StatusCode != 0 {
+		p.statusCode.WithLabelValues(serviceName).Set(float64(report.StatusCode))
+	}
+	p.checkDuration.WithLabelValues(serviceName).Observe(report.Latency.Seconds())
+
+	// у сервиса может быть несколько правил одного типа, по типу берём худший уровень
+	byType := make(map[string]domain.Severity)
+	for _, result := range report.Results {
+		byType[result.RuleType] = max(byType[result.RuleType], result.OK)
+	}
+	for ruleType, severity := range byType {
+		p.ruleSeverity.WithLabelValues(serviceName, ruleType).Set(float64(severity))
+	}
+
+	if !report.CertNotAfter.IsZero() {
+		p.sslDaysLeft.WithLabelValues(serviceName).Set(time.Until(report.CertNotAfter).Hours() / 24)
+	}
+}
+
+func (p *Prometheus) ServiceState(serviceName string, severity domain.Severity) {
+	p.serviceSeverity.WithLabelValues(serviceName).Set(float64(severity))
+}
+
+func (p *Prometheus) QueueDepth(notifier string, depth int) {
+	p.queueDepth.WithLabelValues(notifier).Set(float64(depth))
+}
+
+func (p *Prometheus) Dropped(notifier string) {
+	p.notificationsDropped.WithLabelValues(notifier).Inc()
+}
+
+func (p *Prometheus) NotifySent(notifier string) {
+	p.notificationsSent.WithLabelValues(notifier).Inc()
+}
+
+func (p *Prometheus) NotifyFailed(notifier string) {
+	p.notificationsFailed.WithLabelValues(notifier).Inc()
+}
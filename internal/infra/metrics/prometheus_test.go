@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus(t *testing.T) {
+	t.Run("результаты проверки", func(t *testing.T) {
+		p := NewPrometheus()
+
+		p.CheckCompleted("example.ru", &domain.CheckReport{
+			Results: []domain.CheckResult{
+				{RuleType: "status_code", OK: domain.OK},
+				{RuleType: "header", OK: domain.WARN},
+				{RuleType: "header", OK: domain.OK},
+			},
+			Latency:      250 * time.Millisecond,
+			StatusCode:   200,
+			CertNotAfter: time.Now().Add(10*24*time.Hour + time.Hour),
+		}, nil)
+		p.ServiceState("example.ru", domain.WARN)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(p.serviceUp.WithLabelValues("example.ru")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(p.serviceSeverity.WithLabelValues("example.ru")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(p.ruleSeverity.WithLabelValues("example.ru", "header")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(p.ruleSeverity.WithLabelValues("example.ru", "status_code")))
+		assert.Equal(t, 200.0, testutil.ToFloat64(p.statusCode.WithLabelValues("example.ru")))
+		assert.InDelta(t, 10.0, testutil.ToFloat64(p.sslDaysLeft.WithLabelValues("example.ru")), 0.1)
+		assert.Equal(t, 1, testutil.CollectAndCount(p.checkDuration))
+	})
+
+	t.Run("ошибка запроса", func(t *testing.T) {
+		p := NewPrometheus()
+
+		p.CheckCompleted("example.ru", &domain.CheckReport{}, errors.New("connection refused"))
+
+		assert.Equal(t, 0.0, testutil.ToFloat64(p.serviceUp.WithLabelValues("example.ru")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(p.checkErrors.WithLabelValues("example.ru")))
+		assert.Equal(t, 0, testutil.CollectAndCount(p.checkDuration))
+	})
+
+	t.Run("отдаёт метрики по http", func(t *testing.T) {
+		p := NewPrometheus()
+		p.NotifySent("webhook-0")
+		p.NotifyFailed("email-1")
+
+		rec := httptest.NewRecorder()
+		p.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+		body, _ := io.ReadAll(rec.Body)
+		assert.Contains(t, string(body), `webwatcher_notifications_sent_total{notifier="webhook-0"} 1`)
+		assert.Contains(t, string(body), `webwatcher_notifications_failed_total{notifier="email-1"} 1`)
+	})
+}
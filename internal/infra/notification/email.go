@@ -1,8 +1,8 @@
 package notification
 
 import (
+	"cmp"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"html"
 	"log/slog"
@@ -16,7 +16,7 @@ import (
 const (
 	ROW_TEMPLATE_OK = `<tr>
 	<td style="border: 1px solid #ccc; padding: 8px 12px;">%s</td>
-	<td style="border: 1px solid #ccc; padding: 8px 12px; color: #0a0;">OK</td>
+	<td style="border: 1px solid #ccc; padding: 8px 12px; color: #0a0;">OK%s</td>
 </tr>`
 	ROW_TEMPLATE_FAIL = `<tr>
 	<td style="border: 1px solid #ccc; padding: 8px 12px;">%s</td>
@@ -55,6 +55,28 @@ var levelNameMap = map[domain.Severity]string{
 	domain.CRIT: "критическая ошибка",
 }
 
+// okDetails сообщение успешной проверки в скобках, например значения групп body_regex.
+// Уведомления уходят при смене состояния, поэтому без этого такие значения не видны.
+func okDetails(result domain.CheckResult) string {
+	if result.Message == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s)", result.Message)
+}
+
+// eventTitle заголовок уведомления без имени сервиса, общий для всех получателей.
+func eventTitle(event *domain.AlertEvent) string {
+	switch event.Kind {
+	case domain.EventFlappingStarted:
+		return fmt.Sprintf("состояние часто меняется (%.0f%%), уведомления приостановлены", event.FlapScore)
+	case domain.EventFlappingStopped:
+		return fmt.Sprintf("состояние стабилизировалось (%.0f%%), уведомления возобновлены", event.FlapScore)
+	}
+
+	return fmt.Sprintf("результат проверки проекта - %s", levelNameMap[event.Status])
+}
+
 func NewEmailNotifier(smtp config.SMTPConnection, emailTo []string) domain.Notifier {
 	return &emailNotifier{
 		emailTo: emailTo,
@@ -67,7 +89,7 @@ type emailNotifier struct {
 	smtp    config.SMTPConnection
 }
 
-func (h *emailNotifier) Notify(ctx context.Context, event *domain.AlertEvent) {
+func (h *emailNotifier) Notify(ctx context.Context, event *domain.AlertEvent) error {
 	logger := slog.With("component", "email_notifier")
 	logger.Debug("got event", "event", event)
 
@@ -78,26 +100,33 @@ func (h *emailNotifier) Notify(ctx context.Context, event *domain.AlertEvent) {
 
 	var rows []string
 	for _, result := range event.Results {
-		name := ruleNameMap[result.RuleType]
+		name := ruleName(result.RuleType)
 		if result.OK == domain.OK {
-			rows = append(rows, fmt.Sprintf(ROW_TEMPLATE_OK, html.EscapeString(name)))
+			rows = append(rows, fmt.Sprintf(ROW_TEMPLATE_OK, html.EscapeString(name), html.EscapeString(okDetails(result))))
 		} else {
 			rows = append(rows, fmt.Sprintf(ROW_TEMPLATE_FAIL, html.EscapeString(name), html.EscapeString(result.Message)))
 		}
 	}
 
-	message.SetHeader("Subject", fmt.Sprintf("[%s] результат проверки проекта - %s", event.ServiceName, levelNameMap[event.Status]))
+	message.SetHeader("Subject", fmt.Sprintf("[%s] %s", event.ServiceName, eventTitle(event)))
 
 	message.SetBody("text/html", MESSAGE_TEMPLATE_TOP+strings.Join(rows, "\r\n")+MESSAGE_TEMPLATE_BOTTOM)
 
-	d := gomail.NewDialer(h.smtp.Host, h.smtp.Port, h.smtp.Username, h.smtp.Password)
+	logger.Info("send message", "service_name", event.ServiceName)
 
-	if h.smtp.SkipTLS {
-		d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	sender, err := dialSMTP(ctx, h.smtp)
+	if err != nil {
+		return fmt.Errorf("failed send message: %w", cmp.Or(ctx.Err(), err))
 	}
 
-	logger.Info("send message", "service_name", event.ServiceName)
-	if err := d.DialAndSend(message); err != nil {
-		logger.Error("failed send message", "err", err)
+	if err := gomail.Send(sender, message); err != nil {
+		sender.abort()
+		return fmt.Errorf("failed send message: %w", cmp.Or(ctx.Err(), err))
 	}
+
+	if err := sender.Close(); err != nil {
+		logger.Warn("failed close smtp connection", "err", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSMTP принимает одно соединение и передаёт его handler, возвращает настройки smtp для сервера.
+func serveSMTP(t *testing.T, handler func(conn net.Conn, reader *bufio.Reader)) config.SMTPConnection {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		handler(conn, bufio.NewReader(conn))
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	return config.SMTPConnection{Host: host, Port: portNumber, Username: "robot", From: "robot@example.ru"}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	event := &domain.AlertEvent{
+		ServiceName: "example.ru",
+		Status:      domain.CRIT,
+		Results: []domain.CheckResult{
+			{RuleType: config.TYPE_STATUS_CODE, OK: domain.CRIT, Message: "ожидается статус 200, получен 500"},
+		},
+	}
+
+	t.Run("отправляет письмо", func(t *testing.T) {
+		received := make(chan string, 1)
+		smtp := serveSMTP(t, func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("220 mail.example.ru ESMTP\r\n"))
+
+			var data []string
+			inData := false
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				switch {
+				case inData && line == ".\r\n":
+					inData = false
+					received <- strings.Join(data, "")
+					conn.Write([]byte("250 queued\r\n"))
+				case inData:
+					data = append(data, line)
+				case strings.HasPrefix(line, "EHLO"):
+					conn.Write([]byte("250 mail.example.ru\r\n"))
+				case strings.HasPrefix(line, "DATA"):
+					inData = true
+					conn.Write([]byte("354 go ahead\r\n"))
+				case strings.HasPrefix(line, "QUIT"):
+					conn.Write([]byte("221 bye\r\n"))
+					return
+				default:
+					conn.Write([]byte("250 ok\r\n"))
+				}
+			}
+		})
+
+		err := NewEmailNotifier(smtp, []string{"admin@example.ru"}).Notify(t.Context(), event)
+		require.NoError(t, err)
+
+		message := <-received
+		assert.Contains(t, message, "To: admin@example.ru")
+		assert.Contains(t, message, "Subject: =?UTF-8?q?[example.ru]")
+	})
+
+	t.Run("зависший сервер прерывается по контексту", func(t *testing.T) {
+		smtp := serveSMTP(t, func(conn net.Conn, reader *bufio.Reader) {
+			// приветствие так и не приходит
+			reader.ReadString('\n')
+		})
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := NewEmailNotifier(smtp, []string{"admin@example.ru"}).Notify(ctx, event)
+		assert.ErrorContains(t, err, "failed send message")
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+const SMTP_DIAL_TIMEOUT = 10 * time.Second
+
+// smtpSender gomail.SendCloser поверх соединения, которое прерывается вместе с контекстом:
+// gomail.Dialer контекст не принимает, и зависший сервер держал бы отправку сколько угодно.
+type smtpSender struct {
+	client *smtp.Client
+	stop   func() bool
+}
+
+// dialSMTP подключается как gomail.Dialer: порт 465 — сразу TLS, иначе STARTTLS, если сервер его предлагает.
+// Дедлайн соединения берётся из ctx, отмена ctx обрывает чтение и запись.
+func dialSMTP(ctx context.Context, cfg config.SMTPConnection) (*smtpSender, error) {
+	dialer := &net.Dialer{Timeout: SMTP_DIAL_TIMEOUT}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed connect: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.SkipTLS}
+	if cfg.Port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, err
+	}
+
+	sender := &smtpSender{client: client, stop: stop}
+	if err := sender.hello(cfg, tlsConfig); err != nil {
+		sender.abort()
+		return nil, err
+	}
+
+	return sender, nil
+}
+
+func (s *smtpSender) hello(cfg config.SMTPConnection, tlsConfig *tls.Config) error {
+	if cfg.Port != 465 {
+		if ok, _ := s.client.Extension("STARTTLS"); ok {
+			if err := s.client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	ok, auths := s.client.Extension("AUTH")
+	if !ok || cfg.Username == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	switch {
+	case strings.Contains(auths, "CRAM-MD5"):
+		auth = smtp.CRAMMD5Auth(cfg.Username, cfg.Password)
+	case strings.Contains(auths, "LOGIN") && !strings.Contains(auths, "PLAIN"):
+		auth = &loginAuth{username: cfg.Username, password: cfg.Password}
+	default:
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return s.client.Auth(auth)
+}
+
+func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
+	if err := s.client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		if err := s.client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := msg.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func (s *smtpSender) Close() error {
+	defer s.stop()
+
+	return s.client.Quit()
+}
+
+// abort закрывает соединение без QUIT, после ошибки сервер может не ответить.
+func (s *smtpSender) abort() {
+	s.stop()
+	s.client.Close()
+}
+
+// loginAuth механизм LOGIN для серверов без PLAIN, в net/smtp его нет.
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSuffix(string(fromServer), ":")) {
+	case "username":
+		return []byte(a.username), nil
+	case "password":
+		return []byte(a.password), nil
+	}
+
+	return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+}
@@ -0,0 +1,225 @@
+package notification
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+// TELEGRAM_MAX_ATTEMPTS ограничивает число попыток отправки, когда Bot API отвечает 429.
+const TELEGRAM_MAX_ATTEMPTS = 3
+
+// TELEGRAM_DEFAULT_RETRY_AFTER ожидание в секундах после 429 без retry_after и заголовка Retry-After, например от прокси.
+const TELEGRAM_DEFAULT_RETRY_AFTER = 5
+
+type telegramSendMessageRequest struct {
+	ChatId                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+type telegramResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+func NewTelegramNotifier(cfg config.Notification) domain.Notifier {
+	return &telegramNotifier{
+		apiURL:    strings.TrimRight(cfg.APIURL, "/"),
+		botToken:  cfg.BotToken,
+		chatId:    cfg.ChatId,
+		parseMode: cfg.ParseMode,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		retryAfterUnit: time.Second,
+	}
+}
+
+type telegramNotifier struct {
+	apiURL    string
+	botToken  string
+	chatId    string
+	parseMode string
+	client    *http.Client
+
+	// единица измерения retry_after, в тестах подменяется на миллисекунды
+	retryAfterUnit time.Duration
+}
+
+func (h *telegramNotifier) Notify(ctx context.Context, event *domain.AlertEvent) error {
+	logger := slog.With("component", "telegram_notifier", "service_name", event.ServiceName)
+
+	logger.Debug("got event", "event", event)
+
+	var text string
+	if h.parseMode == config.TELEGRAM_PARSE_MODE_MARKDOWN_V2 {
+		text = renderTelegramMarkdownV2(event)
+	} else {
+		text = renderTelegramHTML(event)
+	}
+
+	body, err := json.Marshal(telegramSendMessageRequest{
+		ChatId:                h.chatId,
+		Text:                  text,
+		ParseMode:             h.parseMode,
+		DisableWebPagePreview: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed marshal message: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := h.send(ctx, body)
+		if err == nil {
+			logger.Info("message sent")
+			return nil
+		}
+
+		if retryAfter == 0 || attempt >= TELEGRAM_MAX_ATTEMPTS {
+			return fmt.Errorf("failed send message after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("telegram rate limit, waiting", "retry_after", retryAfter, "attempt", attempt)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed send message: %w", ctx.Err())
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// send отправляет сообщение через sendMessage. При ответе 429 возвращает
+// время ожидания вместе с ошибкой.
+func (h *telegramNotifier) send(ctx context.Context, body []byte) (time.Duration, error) {
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", h.apiURL, h.botToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// токен входит в url, поэтому не отдаём наружу исходную ошибку целиком
+		return 0, fmt.Errorf("failed request: %s", strings.ReplaceAll(err.Error(), h.botToken, "***"))
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed read response body: %w", err)
+	}
+
+	// 429 может прийти и без json, например от прокси перед Bot API, поэтому он повторяется в любом случае
+	var apiResp telegramResponse
+	parseErr := json.Unmarshal(respBody, &apiResp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return h.retryAfter(resp.Header, apiResp), fmt.Errorf("too many requests: %s", cmp.Or(apiResp.Description, resp.Status))
+	}
+
+	if parseErr != nil {
+		return 0, fmt.Errorf("failed parse response with status %d: %w", resp.StatusCode, parseErr)
+	}
+
+	if apiResp.Ok {
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("telegram api error %d: %s", apiResp.ErrorCode, apiResp.Description)
+}
+
+// retryAfter ожидание после 429: parameters.retry_after, заголовок Retry-After в секундах или TELEGRAM_DEFAULT_RETRY_AFTER.
+func (h *telegramNotifier) retryAfter(header http.Header, apiResp telegramResponse) time.Duration {
+	seconds := apiResp.Parameters.RetryAfter
+	if seconds <= 0 {
+		seconds, _ = strconv.Atoi(header.Get("Retry-After"))
+	}
+	if seconds <= 0 {
+		seconds = TELEGRAM_DEFAULT_RETRY_AFTER
+	}
+
+	return time.Duration(seconds) * h.retryAfterUnit
+}
+
+func renderTelegramHTML(event *domain.AlertEvent) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<b>[%s]</b> %s\n", html.EscapeString(event.ServiceName), html.EscapeString(eventTitle(event)))
+
+	for _, result := range event.Results {
+		name := html.EscapeString(ruleName(result.RuleType))
+		if result.OK == domain.OK {
+			fmt.Fprintf(&b, "\n✅ %s: OK%s", name, html.EscapeString(okDetails(result)))
+		} else {
+			fmt.Fprintf(&b, "\n%s %s: %s", severityIcon(result.OK), name, html.EscapeString(result.Message))
+		}
+	}
+
+	return b.String()
+}
+
+func renderTelegramMarkdownV2(event *domain.AlertEvent) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*\\[%s\\]* %s\n", escapeMarkdownV2(event.ServiceName), escapeMarkdownV2(eventTitle(event)))
+
+	for _, result := range event.Results {
+		name := escapeMarkdownV2(ruleName(result.RuleType))
+		if result.OK == domain.OK {
+			fmt.Fprintf(&b, "\n✅ %s: OK%s", name, escapeMarkdownV2(okDetails(result)))
+		} else {
+			fmt.Fprintf(&b, "\n%s %s: %s", severityIcon(result.OK), name, escapeMarkdownV2(result.Message))
+		}
+	}
+
+	return b.String()
+}
+
+// escapeMarkdownV2 экранирует символы, которые Bot API считает разметкой в режиме MarkdownV2.
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("_*[]()~`>#+-=|{}.!\\", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func ruleName(ruleType string) string {
+	if name, ok := ruleNameMap[ruleType]; ok {
+		return name
+	}
+
+	return ruleType
+}
+
+func severityIcon(severity domain.Severity) string {
+	if severity == domain.WARN {
+		return "⚠️"
+	}
+
+	return "❌"
+}
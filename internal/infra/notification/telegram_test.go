@@ -0,0 +1,175 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegramNotifier(t *testing.T) {
+	event := &domain.AlertEvent{
+		ServiceName: "example.ru",
+		Status:      domain.CRIT,
+		Results: []domain.CheckResult{
+			{RuleType: config.TYPE_STATUS_CODE, OK: domain.CRIT, Message: "ожидается статус 200, получен <500>"},
+			{RuleType: config.TYPE_MAX_LATENCY, OK: domain.OK},
+		},
+	}
+
+	newNotifier := func(apiURL string) *telegramNotifier {
+		notifier := NewTelegramNotifier(config.Notification{
+			APIURL:    apiURL,
+			BotToken:  "123:token",
+			ChatId:    "-100500",
+			ParseMode: config.TELEGRAM_PARSE_MODE_HTML,
+			Timeout:   time.Second,
+		}).(*telegramNotifier)
+		notifier.retryAfterUnit = time.Millisecond
+
+		return notifier
+	}
+
+	t.Run("отправляет сообщение в html", func(t *testing.T) {
+		var gotPath string
+		var got telegramSendMessageRequest
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&got)
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		}))
+		defer server.Close()
+
+		err := newNotifier(server.URL+"/").Notify(t.Context(), event)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "/bot123:token/sendMessage", gotPath)
+		assert.Equal(t, "-100500", got.ChatId)
+		assert.Equal(t, "HTML", got.ParseMode)
+		assert.Equal(t, "<b>[example.ru]</b> результат проверки проекта - критическая ошибка\n"+
+			"\n❌ Код ответа: ожидается статус 200, получен &lt;500&gt;"+
+			"\n✅ Превышение времени ответа: OK", got.Text)
+	})
+
+	t.Run("повторяет отправку после 429 с retry_after", func(t *testing.T) {
+		calls := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		}))
+		defer server.Close()
+
+		err := newNotifier(server.URL).Notify(t.Context(), event)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("повторяет отправку после 429 без json", func(t *testing.T) {
+		var calls int
+		var waits []time.Duration
+		last := time.Now()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			waits = append(waits, time.Since(last))
+			last = time.Now()
+
+			switch calls {
+			case 1:
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte("<html>slow down</html>"))
+			case 2:
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				w.Write([]byte(`{"ok":true,"result":{}}`))
+			}
+		}))
+		defer server.Close()
+
+		err := newNotifier(server.URL).Notify(t.Context(), event)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, calls)
+		// retryAfterUnit в тестах — миллисекунда: 30 из заголовка и TELEGRAM_DEFAULT_RETRY_AFTER по умолчанию
+		assert.GreaterOrEqual(t, waits[1], 30*time.Millisecond)
+		assert.GreaterOrEqual(t, waits[2], TELEGRAM_DEFAULT_RETRY_AFTER*time.Millisecond)
+	})
+
+	t.Run("не повторяет отправку при прочих ошибках", func(t *testing.T) {
+		calls := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+		}))
+		defer server.Close()
+
+		notifier := newNotifier(server.URL)
+
+		_, err := notifier.send(t.Context(), []byte(`{}`))
+		assert.ErrorContains(t, err, "chat not found")
+
+		err = notifier.Notify(t.Context(), event)
+		assert.ErrorContains(t, err, "chat not found")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("прекращает попытки после лимита", func(t *testing.T) {
+		calls := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":1}}`))
+		}))
+		defer server.Close()
+
+		err := newNotifier(server.URL).Notify(t.Context(), event)
+		assert.ErrorContains(t, err, "too many requests")
+
+		assert.Equal(t, TELEGRAM_MAX_ATTEMPTS, calls)
+	})
+}
+
+func TestRenderTelegramMarkdownV2(t *testing.T) {
+	event := &domain.AlertEvent{
+		ServiceName: "api.example.ru",
+		Status:      domain.WARN,
+		Results: []domain.CheckResult{
+			{RuleType: config.TYPE_MAX_LATENCY, OK: domain.WARN, Message: "ответ сервера превысил 200ms и составил 500.5ms"},
+		},
+	}
+
+	assert.Equal(t, "*\\[api\\.example\\.ru\\]* результат проверки проекта \\- обнаружены прежупреждения\n"+
+		"\n⚠️ Превышение времени ответа: ответ сервера превысил 200ms и составил 500\\.5ms", renderTelegramMarkdownV2(event))
+}
+
+func TestRenderTelegramHTML_OKDetails(t *testing.T) {
+	event := &domain.AlertEvent{
+		ServiceName: "api.example.ru",
+		Status:      domain.CRIT,
+		Results: []domain.CheckResult{
+			{RuleType: config.TYPE_STATUS_CODE, OK: domain.CRIT, Message: "ожидается статус 200, получен 500"},
+			{RuleType: config.TYPE_BODY_REGEX, OK: domain.OK, Message: "version=<1.2.3>"},
+		},
+	}
+
+	assert.Equal(t, "<b>[api.example.ru]</b> результат проверки проекта - критическая ошибка\n"+
+		"\n❌ Код ответа: ожидается статус 200, получен 500"+
+		"\n✅ body_regex: OK (version=&lt;1.2.3&gt;)", renderTelegramHTML(event))
+}
@@ -1,24 +1,127 @@
 package notification
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"time"
 
+	"github.com/kias-hack/web-watcher/internal/config"
 	"github.com/kias-hack/web-watcher/internal/domain"
 )
 
-func NewWebHookNotifier(url string) domain.Notifier {
+// WEBHOOK_PAYLOAD_VERSION версия формата тела запроса вебхука.
+// Увеличивается при любом несовместимом изменении webhookPayload.
+const WEBHOOK_PAYLOAD_VERSION = 1
+
+type webhookPayload struct {
+	Version   int                   `json:"version"`
+	Event     string                `json:"event"`
+	Service   string                `json:"service"`
+	Severity  string                `json:"severity"`
+	FlapScore float64               `json:"flap_score,omitempty"`
+	CheckedAt time.Time             `json:"checked_at"`
+	SentAt    time.Time             `json:"sent_at"`
+	Results   []webhookResultRecord `json:"results"`
+}
+
+type webhookResultRecord struct {
+	RuleType string `json:"rule_type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func NewWebHookNotifier(cfg config.Notification) domain.Notifier {
 	return &webHookNotifier{
-		url: url,
+		url:     cfg.URL,
+		method:  cfg.Method,
+		headers: cfg.Headers,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
 	}
 }
 
 type webHookNotifier struct {
-	url string
+	url     string
+	method  string
+	headers map[string]string
+	client  *http.Client
 }
 
-func (h *webHookNotifier) Notify(ctx context.Context, event *domain.AlertEvent) {
-	logger := slog.With("component", "webhook_notifier")
+func (h *webHookNotifier) Notify(ctx context.Context, event *domain.AlertEvent) error {
+	logger := slog.With("component", "webhook_notifier", "service_name", event.ServiceName)
 
 	logger.Debug("got event", "event", event)
+
+	body, err := json.Marshal(newWebhookPayload(event, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed marshal payload: %w", err)
+	}
+
+	if err := h.send(ctx, body); err != nil {
+		return fmt.Errorf("failed send webhook: %w", err)
+	}
+
+	logger.Info("webhook sent")
+
+	return nil
+}
+
+func (h *webHookNotifier) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, h.method, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range h.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// тело читаем, чтобы соединение вернулось в пул
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func newWebhookPayload(event *domain.AlertEvent, sentAt time.Time) webhookPayload {
+	results := make([]webhookResultRecord, 0, len(event.Results))
+	for _, result := range event.Results {
+		results = append(results, webhookResultRecord{
+			RuleType: result.RuleType,
+			Severity: result.OK.String(),
+			Message:  result.Message,
+		})
+	}
+
+	var flapScore float64
+	if event.Kind != domain.EventAlert {
+		flapScore = event.FlapScore
+	}
+
+	return webhookPayload{
+		Version:   WEBHOOK_PAYLOAD_VERSION,
+		Event:     event.Kind.String(),
+		Service:   event.ServiceName,
+		Severity:  event.Status.String(),
+		CheckedAt: event.CheckedAt,
+		FlapScore: flapScore,
+		SentAt:    sentAt,
+		Results:   results,
+	}
 }
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebHookNotifier(t *testing.T) {
+	checkedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := &domain.AlertEvent{
+		ServiceName: "example.ru",
+		Status:      domain.CRIT,
+		CheckedAt:   checkedAt,
+		Results: []domain.CheckResult{
+			{RuleType: config.TYPE_STATUS_CODE, OK: domain.CRIT, Message: "ожидается статус 200, получен 500"},
+			{RuleType: config.TYPE_MAX_LATENCY, OK: domain.OK},
+		},
+	}
+
+	t.Run("отправляет документ с версией и результатами", func(t *testing.T) {
+		var gotMethod, gotContentType, gotToken string
+		var got map[string]any
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotMethod = r.Method
+			gotContentType = r.Header.Get("Content-Type")
+			gotToken = r.Header.Get("X-Token")
+
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &got)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notifier := NewWebHookNotifier(config.Notification{
+			URL:     server.URL,
+			Method:  http.MethodPut,
+			Headers: map[string]string{"X-Token": "secret"},
+			Timeout: time.Second,
+		})
+		err := notifier.Notify(t.Context(), event)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.MethodPut, gotMethod)
+		assert.Equal(t, "application/json", gotContentType)
+		assert.Equal(t, "secret", gotToken)
+
+		assert.Equal(t, float64(WEBHOOK_PAYLOAD_VERSION), got["version"])
+		assert.Equal(t, "alert", got["event"])
+		assert.NotContains(t, got, "flap_score")
+		assert.Equal(t, "example.ru", got["service"])
+		assert.Equal(t, "crit", got["severity"])
+		assert.Equal(t, "2026-01-02T03:04:05Z", got["checked_at"])
+		assert.NotEmpty(t, got["sent_at"])
+		assert.Equal(t, []any{
+			map[string]any{"rule_type": "status_code", "severity": "crit", "message": "ожидается статус 200, получен 500"},
+			map[string]any{"rule_type": "max_latency", "severity": "ok", "message": ""},
+		}, got["results"])
+	})
+
+	t.Run("событие флаппинга содержит оценку", func(t *testing.T) {
+		var got map[string]any
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &got)
+		}))
+		defer server.Close()
+
+		flapping := *event
+		flapping.Kind = domain.EventFlappingStarted
+		flapping.FlapScore = 42.5
+
+		notifier := NewWebHookNotifier(config.Notification{URL: server.URL, Method: http.MethodPost, Timeout: time.Second})
+		err := notifier.Notify(t.Context(), &flapping)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "flapping_started", got["event"])
+		assert.Equal(t, 42.5, got["flap_score"])
+	})
+
+	t.Run("ошибочный статус ответа", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		notifier := &webHookNotifier{url: server.URL, method: http.MethodPost, client: http.DefaultClient}
+
+		err := notifier.send(t.Context(), []byte(`{}`))
+		assert.ErrorContains(t, err, "unexpected response status: 500")
+	})
+
+	t.Run("таймаут запроса", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		notifier := &webHookNotifier{url: server.URL, method: http.MethodPost, client: &http.Client{Timeout: 50 * time.Millisecond}}
+
+		err := notifier.send(t.Context(), []byte(`{}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,285 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+const (
+	historyFileExt    = ".jsonl"
+	historyDateLayout = "2006-01-02"
+	historyDay        = 24 * time.Hour
+)
+
+// NewFileResultStore создаёт историю проверок в dir: у каждого сервиса свой каталог,
+// записи одного дня (UTC) дописываются строками в файл <YYYY-MM-DD>.jsonl.
+// Записи старше retention удаляются при старте и при смене дня, retention 0 — хранить всё.
+func NewFileResultStore(dir string, retention time.Duration) (domain.ResultStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed create history dir: %w", err)
+	}
+
+	store := &fileResultStore{
+		dir:       dir,
+		retention: retention,
+		lastDay:   time.Now().UTC().Format(historyDateLayout),
+	}
+
+	if retention > 0 {
+		if err := store.Prune(context.Background(), time.Now().Add(-retention)); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+type fileResultStore struct {
+	dir       string
+	retention time.Duration
+	lastDay   string
+
+	mu sync.Mutex
+}
+
+func (s *fileResultStore) Append(ctx context.Context, record domain.HistoryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := record.CheckedAt.UTC().Format(historyDateLayout)
+	if s.retention > 0 && day > s.lastDay {
+		s.lastDay = day
+		if err := s.prune(time.Now().Add(-s.retention)); err != nil {
+			slog.Error("failed prune history", "component", "file_result_store", "err", err)
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed marshal history record: %w", err)
+	}
+
+	serviceDir := s.serviceDir(record.ServiceName)
+	if err := os.MkdirAll(serviceDir, 0o750); err != nil {
+		return fmt.Errorf("failed create service history dir: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(serviceDir, day+historyFileExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed open history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed write history record: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileResultStore) Query(ctx context.Context, serviceName string, from, to time.Time) ([]domain.HistoryRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, err := s.listDays(s.serviceDir(serviceName))
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay := from.UTC().Format(historyDateLayout)
+	toDay := to.UTC().Format(historyDateLayout)
+
+	var result []domain.HistoryRecord
+	for _, day := range days {
+		if day < fromDay || day > toDay {
+			continue
+		}
+
+		records, err := readHistoryFile(filepath.Join(s.serviceDir(serviceName), day+historyFileExt))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if record.CheckedAt.Before(from) || record.CheckedAt.After(to) {
+				continue
+			}
+
+			result = append(result, record)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CheckedAt.Before(result[j].CheckedAt)
+	})
+
+	return result, nil
+}
+
+func (s *fileResultStore) Prune(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.prune(before)
+}
+
+// prune удаляет файлы дней целиком раньше before, а файл дня, на который приходится before,
+// переписывает без устаревших записей.
+func (s *fileResultStore) prune(before time.Time) error {
+	services, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed read history dir: %w", err)
+	}
+
+	beforeDay := before.UTC().Format(historyDateLayout)
+
+	for _, service := range services {
+		if !service.IsDir() {
+			continue
+		}
+
+		serviceDir := filepath.Join(s.dir, service.Name())
+
+		days, err := s.listDays(serviceDir)
+		if err != nil {
+			return err
+		}
+
+		for _, day := range days {
+			path := filepath.Join(serviceDir, day+historyFileExt)
+
+			switch {
+			case day < beforeDay:
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("failed remove history file: %w", err)
+				}
+			case day == beforeDay:
+				if err := compactHistoryFile(path, before); err != nil {
+					return err
+				}
+			}
+		}
+
+		// каталог удалится, только если в нём не осталось файлов
+		os.Remove(serviceDir)
+	}
+
+	return nil
+}
+
+func (s *fileResultStore) listDays(serviceDir string) ([]string, error) {
+	files, err := os.ReadDir(serviceDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed read service history dir: %w", err)
+	}
+
+	var days []string
+	for _, file := range files {
+		day, ok := strings.CutSuffix(file.Name(), historyFileExt)
+		if file.IsDir() || !ok {
+			continue
+		}
+
+		if _, err := time.Parse(historyDateLayout, day); err != nil {
+			continue
+		}
+
+		days = append(days, day)
+	}
+
+	sort.Strings(days)
+
+	return days, nil
+}
+
+// serviceDir экранирует имя сервиса, чтобы оно не могло выйти за пределы каталога истории.
+func (s *fileResultStore) serviceDir(serviceName string) string {
+	name := url.PathEscape(serviceName)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+
+	return filepath.Join(s.dir, name)
+}
+
+func readHistoryFile(path string) ([]domain.HistoryRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed read history file: %w", err)
+	}
+
+	var result []domain.HistoryRecord
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record domain.HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// строка могла обрезаться при падении процесса во время записи
+			slog.Warn("skip broken history record", "component", "file_result_store", "file", path, "err", err)
+			continue
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+func compactHistoryFile(path string, before time.Time) error {
+	records, err := readHistoryFile(path)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	var kept int
+	for _, record := range records {
+		if record.CheckedAt.Before(before) {
+			continue
+		}
+		kept++
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed marshal history record: %w", err)
+		}
+
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	if kept == len(records) {
+		return nil
+	}
+
+	if kept == 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed remove history file: %w", err)
+		}
+		return nil
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
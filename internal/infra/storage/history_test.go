@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileResultStore(t *testing.T) {
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	record := func(service string, checkedAt time.Time, severity domain.Severity) domain.HistoryRecord {
+		return domain.HistoryRecord{
+			ServiceName: service,
+			CheckedAt:   checkedAt,
+			Latency:     120 * time.Millisecond,
+			StatusCode:  200,
+			Severity:    severity,
+			Results:     []domain.CheckResult{{RuleType: "status_code", OK: severity}},
+		}
+	}
+
+	t.Run("выборка по сервису и интервалу времени", func(t *testing.T) {
+		store, err := NewFileResultStore(t.TempDir(), 0)
+		require.NoError(t, err)
+
+		for _, r := range []domain.HistoryRecord{
+			record("example.ru", day.Add(23*time.Hour), domain.OK),
+			record("example.ru", day.Add(25*time.Hour), domain.CRIT),
+			record("example.ru", day.Add(49*time.Hour), domain.OK),
+			record("example.com", day.Add(25*time.Hour), domain.WARN),
+		} {
+			require.NoError(t, store.Append(t.Context(), r))
+		}
+
+		got, err := store.Query(t.Context(), "example.ru", day.Add(22*time.Hour), day.Add(26*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, domain.OK, got[0].Severity)
+		assert.Equal(t, domain.CRIT, got[1].Severity)
+		assert.Equal(t, 120*time.Millisecond, got[1].Latency)
+		assert.Equal(t, 200, got[1].StatusCode)
+
+		got, err = store.Query(t.Context(), "unknown", day, day.Add(72*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("удаление устаревших записей", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewFileResultStore(dir, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Append(t.Context(), record("example.ru", day.Add(time.Hour), domain.OK)))
+		require.NoError(t, store.Append(t.Context(), record("example.ru", day.Add(25*time.Hour), domain.OK)))
+		require.NoError(t, store.Append(t.Context(), record("example.ru", day.Add(30*time.Hour), domain.CRIT)))
+
+		require.NoError(t, store.Prune(t.Context(), day.Add(26*time.Hour)))
+
+		got, err := store.Query(t.Context(), "example.ru", day, day.Add(72*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, domain.CRIT, got[0].Severity)
+
+		_, err = os.Stat(filepath.Join(dir, "example.ru", "2026-01-02.jsonl"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("имя сервиса не выходит за каталог истории", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewFileResultStore(filepath.Join(dir, "history"), 0)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Append(t.Context(), record("..", day, domain.OK)))
+		require.NoError(t, store.Append(t.Context(), record("../evil", day, domain.OK)))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		got, err := store.Query(t.Context(), "../evil", day, day.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+const outboxEntryExt = ".json"
+
+// NewFileOutbox создаёт outbox, в котором каждое событие хранится отдельным json-файлом в dir,
+// а dead-letter записи дописываются строками в deadLetterPath.
+func NewFileOutbox(dir string, deadLetterPath string) (domain.Outbox, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed create outbox dir: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(deadLetterPath), 0o750); err != nil {
+		return nil, fmt.Errorf("failed create dead letter dir: %w", err)
+	}
+
+	return &fileOutbox{
+		dir:            dir,
+		deadLetterPath: deadLetterPath,
+	}, nil
+}
+
+type fileOutbox struct {
+	dir            string
+	deadLetterPath string
+
+	mu sync.Mutex
+}
+
+type deadLetterRecord struct {
+	domain.OutboxEntry
+	DeadAt time.Time
+}
+
+func (o *fileOutbox) Put(ctx context.Context, entry domain.OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed marshal outbox entry: %w", err)
+	}
+
+	return writeFileAtomic(o.entryPath(entry.ID), data)
+}
+
+func (o *fileOutbox) List(ctx context.Context) ([]domain.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed read outbox dir: %w", err)
+	}
+
+	var result []domain.OutboxEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), outboxEntryExt) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(o.dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed read outbox entry: %w", err)
+		}
+
+		var entry domain.OutboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			slog.Warn("skip broken outbox entry", "component", "file_outbox", "file", file.Name(), "err", err)
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+func (o *fileOutbox) Remove(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.remove(id)
+}
+
+func (o *fileOutbox) DeadLetter(ctx context.Context, entry domain.OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	data, err := json.Marshal(deadLetterRecord{OutboxEntry: entry, DeadAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed marshal dead letter: %w", err)
+	}
+
+	file, err := os.OpenFile(o.deadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed open dead letter log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed write dead letter: %w", err)
+	}
+
+	return o.remove(entry.ID)
+}
+
+func (o *fileOutbox) remove(id string) error {
+	if err := os.Remove(o.entryPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed remove outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+func (o *fileOutbox) entryPath(id string) string {
+	return filepath.Join(o.dir, filepath.Base(id)+outboxEntryExt)
+}
+
+// writeFileAtomic пишет во временный файл и переименовывает его, чтобы при падении
+// процесса на диске не остался обрезанный json.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed write temp file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed sync temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed rename temp file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileOutbox(t *testing.T) {
+	dir := t.TempDir()
+	deadLetterPath := filepath.Join(dir, "dead", "dead-letter.jsonl")
+
+	outbox, err := NewFileOutbox(filepath.Join(dir, "outbox"), deadLetterPath)
+	require.NoError(t, err)
+
+	now := time.Now()
+	first := domain.OutboxEntry{
+		ID:        "1",
+		Notifier:  "email-0",
+		CreatedAt: now,
+		Event: domain.AlertEvent{
+			ServiceName: "example.ru",
+			Status:      domain.CRIT,
+			Results:     []domain.CheckResult{{RuleType: "status_code", OK: domain.CRIT, Message: "ожидается статус 200, получен 500"}},
+		},
+	}
+	second := domain.OutboxEntry{ID: "2", Notifier: "webhook-1", CreatedAt: now.Add(time.Second)}
+
+	require.NoError(t, outbox.Put(t.Context(), second))
+	require.NoError(t, outbox.Put(t.Context(), first))
+
+	t.Run("записи переживают пересоздание outbox и отсортированы по времени", func(t *testing.T) {
+		reopened, err := NewFileOutbox(filepath.Join(dir, "outbox"), deadLetterPath)
+		require.NoError(t, err)
+
+		entries, err := reopened.List(t.Context())
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "1", entries[0].ID)
+		assert.Equal(t, first.Event.Results, entries[0].Event.Results)
+		assert.Equal(t, "2", entries[1].ID)
+	})
+
+	t.Run("dead-letter дописывает строку и удаляет запись", func(t *testing.T) {
+		first.Attempts = 5
+		require.NoError(t, outbox.DeadLetter(t.Context(), first))
+
+		entries, err := outbox.List(t.Context())
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "2", entries[0].ID)
+
+		data, err := os.ReadFile(deadLetterPath)
+		require.NoError(t, err)
+
+		var record deadLetterRecord
+		require.NoError(t, json.Unmarshal(data, &record))
+		assert.Equal(t, "1", record.ID)
+		assert.Equal(t, 5, record.Attempts)
+		assert.False(t, record.DeadAt.IsZero())
+	})
+
+	t.Run("удаление отсутствующей записи не ошибка", func(t *testing.T) {
+		assert.NoError(t, outbox.Remove(t.Context(), "2"))
+		assert.NoError(t, outbox.Remove(t.Context(), "2"))
+	})
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+const STATE_FILE_VERSION = 1
+
+type stateFile struct {
+	Version  int
+	Services map[string]domain.ServiceStatus
+}
+
+// NewFileStateStore создаёт хранилище состояний в одном json-файле path.
+// Если файл уже есть, состояния из него читаются сразу, чтобы Save не затёр остальные сервисы.
+func NewFileStateStore(path string) (domain.StateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed create state dir: %w", err)
+	}
+
+	store := &fileStateStore{
+		path:   path,
+		states: make(map[string]domain.ServiceStatus),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed read state file: %w", err)
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed parse state file: %w", err)
+	}
+
+	if file.Version != STATE_FILE_VERSION {
+		return nil, fmt.Errorf("unsupported state file version: %d", file.Version)
+	}
+
+	if file.Services != nil {
+		store.states = file.Services
+	}
+
+	return store, nil
+}
+
+type fileStateStore struct {
+	path   string
+	states map[string]domain.ServiceStatus
+
+	mu sync.Mutex
+}
+
+func (s *fileStateStore) Load(ctx context.Context) (map[string]domain.ServiceStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return maps.Clone(s.states), nil
+}
+
+func (s *fileStateStore) Save(ctx context.Context, serviceName string, status domain.ServiceStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[serviceName] = status
+
+	data, err := json.Marshal(stateFile{Version: STATE_FILE_VERSION, Services: s.states})
+	if err != nil {
+		return fmt.Errorf("failed marshal state: %w", err)
+	}
+
+	return writeFileAtomic(s.path, data)
+}
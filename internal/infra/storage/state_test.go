@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStateStore(t *testing.T) {
+	status := domain.ServiceStatus{
+		LastSent: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		CheckResults: []domain.CheckResult{
+			{RuleType: "status_code", OK: domain.CRIT, Message: "ожидается статус 200, получен 500"},
+		},
+		StateHistory: []domain.Severity{domain.OK, domain.CRIT},
+		FlapScore:    30,
+	}
+
+	t.Run("нет файла — пустое состояние", func(t *testing.T) {
+		store, err := NewFileStateStore(filepath.Join(t.TempDir(), "state", "state.json"))
+		require.NoError(t, err)
+
+		states, err := store.Load(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, states)
+	})
+
+	t.Run("состояние переживает перезапуск", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+
+		store, err := NewFileStateStore(path)
+		require.NoError(t, err)
+		require.NoError(t, store.Save(t.Context(), "example.ru", status))
+		require.NoError(t, store.Save(t.Context(), "example.com", domain.ServiceStatus{}))
+
+		reopened, err := NewFileStateStore(path)
+		require.NoError(t, err)
+
+		states, err := reopened.Load(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, states, 2)
+		assert.True(t, status.LastSent.Equal(states["example.ru"].LastSent))
+		assert.Equal(t, status.CheckResults, states["example.ru"].CheckResults)
+		assert.Equal(t, status.StateHistory, states["example.ru"].StateHistory)
+		assert.Equal(t, status.FlapScore, states["example.ru"].FlapScore)
+	})
+
+	t.Run("битый файл", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o640))
+
+		_, err := NewFileStateStore(path)
+		assert.ErrorContains(t, err, "failed parse state file")
+	})
+}
@@ -0,0 +1,127 @@
+package tcpcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+// MAX_RESPONSE_SIZE больше этого ответ не читается, даже если expect ещё не совпал
+const MAX_RESPONSE_SIZE = 64 * 1024
+
+type Dialer interface {
+	DialContext(ctx context.Context, network string, address string) (net.Conn, error)
+}
+
+// NewChecker проверяет сервисы типа tcp: подключение, отправка send и чтение ответа до совпадения с expect.
+func NewChecker(dialer Dialer, timeout time.Duration) domain.ServiceChecker {
+	return &TCPServiceChecker{
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+type TCPServiceChecker struct {
+	dialer  Dialer
+	timeout time.Duration
+}
+
+func (c *TCPServiceChecker) ServiceCheck(ctx context.Context, service *domain.Service) (*domain.CheckReport, error) {
+	logger := slog.With("component", "tcpservicechecker", "service_name", service.Name, "address", service.Address)
+
+	logger.Debug("starts service check")
+
+	// таймаут только на обмен с сервером, правила получают исходный ctx
+	exchangeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var expect *regexp.Regexp
+	if service.TCP.Expect != "" {
+		re, err := regexp.Compile(service.TCP.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expect: %w", err)
+		}
+		expect = re
+	}
+
+	start := time.Now()
+	conn, err := c.dialer.DialContext(exchangeCtx, "tcp", service.Address)
+	latency := time.Since(start)
+	if err != nil {
+		return nil, fmt.Errorf("failed connect: %w", err)
+	}
+	defer conn.Close()
+
+	logger.Debug("connected", "latency", latency)
+
+	if deadline, ok := exchangeCtx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if service.TCP.Send != "" {
+		if _, err := io.WriteString(conn, service.TCP.Send); err != nil {
+			return nil, fmt.Errorf("failed send payload: %w", err)
+		}
+	}
+
+	var response []byte
+	if expect != nil {
+		response, err = readResponse(conn, expect)
+		if err != nil {
+			return nil, fmt.Errorf("failed read response: %w", err)
+		}
+
+		logger.Debug("got response", "size", len(response))
+	}
+	cancel()
+
+	// max_latency сравнивается со временем подключения, ожидание ответа в него не входит
+	checkInput := &domain.CheckInput{
+		Latency: latency,
+		Body:    response,
+	}
+
+	var result []domain.CheckResult
+	for _, rule := range service.Rules {
+		result = append(result, rule.Check(ctx, checkInput))
+	}
+
+	return &domain.CheckReport{
+		Results: result,
+		Latency: latency,
+	}, nil
+}
+
+// readResponse читает, пока ответ не совпадёт с expect, сервер не закроет соединение или не истечёт таймаут.
+// Закрытие и таймаут не ошибка: несовпавший ответ разбирает правило tcp_response.
+func readResponse(conn net.Conn, expect *regexp.Regexp) ([]byte, error) {
+	var response []byte
+	buf := make([]byte, 4096)
+
+	for len(response) < MAX_RESPONSE_SIZE {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+
+		if expect.Match(response) {
+			return response, nil
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, os.ErrDeadlineExceeded) {
+			return response, nil
+		}
+
+		if err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
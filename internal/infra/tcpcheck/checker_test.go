@@ -0,0 +1,138 @@
+package tcpcheck
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve принимает одно соединение и проигрывает на нём диалог сервера.
+func serve(t *testing.T, dialog func(conn net.Conn, reader *bufio.Reader)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		dialog(conn, bufio.NewReader(conn))
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestTCPServiceChecker(t *testing.T) {
+	checker := NewChecker(&net.Dialer{}, 500*time.Millisecond)
+
+	t.Run("redis ping", func(t *testing.T) {
+		address := serve(t, func(conn net.Conn, reader *bufio.Reader) {
+			if line, _ := reader.ReadString('\n'); line == "PING\r\n" {
+				conn.Write([]byte("+PONG\r\n"))
+			}
+		})
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Type:    config.SERVICE_TYPE_TCP,
+			Address: address,
+			TCP:     domain.TCPExchange{Send: "PING\r\n", Expect: `^\+PONG`},
+			Rules: []domain.CheckRule{
+				domain.NewTCPResponseRule(`^\+PONG`),
+				domain.NewLatencyRule(1000),
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Greater(t, report.Latency, time.Duration(0))
+		for _, result := range report.Results {
+			assert.Equal(t, domain.OK, result.OK, result.RuleType+": "+result.Message)
+		}
+	})
+
+	t.Run("баннер приходит частями", func(t *testing.T) {
+		address := serve(t, func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("SSH-2.0-"))
+			time.Sleep(50 * time.Millisecond)
+			conn.Write([]byte("OpenSSH_9.6\r\n"))
+			// соединение остаётся открытым, чтение должно закончиться на совпадении
+			reader.ReadString('\n')
+		})
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			TCP:     domain.TCPExchange{Expect: `^SSH-2\.0-(?P<version>\S+)\r\n`},
+			Rules: []domain.CheckRule{
+				domain.NewTCPResponseRule(`^SSH-2\.0-\S+\r\n`),
+				domain.NewBodyRegexRule(`OpenSSH_(?P<version>[\d.]+)`),
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.OK, report.Results[0].OK, report.Results[0].Message)
+		assert.Equal(t, "version=9.6", report.Results[1].Message)
+	})
+
+	t.Run("ответ не совпал до закрытия соединения", func(t *testing.T) {
+		address := serve(t, func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("554 No SMTP service here\r\n"))
+		})
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			TCP:     domain.TCPExchange{Expect: `^220 `},
+			Rules:   []domain.CheckRule{domain.NewTCPResponseRule(`^220 `)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.CRIT, report.Results[0].OK)
+		assert.Contains(t, report.Results[0].Message, "554 No SMTP service here")
+	})
+
+	t.Run("сервер молчит до таймаута", func(t *testing.T) {
+		address := serve(t, func(conn net.Conn, reader *bufio.Reader) {
+			reader.ReadString('\n')
+		})
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			TCP:     domain.TCPExchange{Expect: `^220 `},
+			Rules:   []domain.CheckRule{domain.NewTCPResponseRule(`^220 `)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.CRIT, report.Results[0].OK)
+		assert.Contains(t, report.Results[0].Message, "сервер ничего не ответил")
+	})
+
+	t.Run("только подключение", func(t *testing.T) {
+		address := serve(t, func(conn net.Conn, reader *bufio.Reader) {})
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			Rules:   []domain.CheckRule{domain.NewLatencyRule(1000)},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.OK, report.Results[0].OK)
+	})
+
+	t.Run("порт закрыт", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		_, err = checker.ServiceCheck(t.Context(), &domain.Service{Address: address})
+		assert.ErrorContains(t, err, "failed connect")
+	})
+}
@@ -0,0 +1,113 @@
+package tlscheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+type Dialer interface {
+	DialContext(ctx context.Context, network string, address string) (net.Conn, error)
+}
+
+// NewChecker проверяет сервисы типа tls: подключение, STARTTLS при необходимости и рукопожатие.
+// С tls_skip_verify сервиса недоверенный сертификат не прерывает проверку, его разбирают правила.
+func NewChecker(dialer Dialer, timeout time.Duration) domain.ServiceChecker {
+	return &TLSServiceChecker{
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+type TLSServiceChecker struct {
+	dialer  Dialer
+	timeout time.Duration
+}
+
+func (c *TLSServiceChecker) ServiceCheck(ctx context.Context, service *domain.Service) (*domain.CheckReport, error) {
+	logger := slog.With("component", "tlsservicechecker", "service_name", service.Name, "address", service.Address)
+
+	logger.Debug("starts service check")
+
+	// таймаут только на рукопожатие: tls_policy и tls_certificate подключаются заново
+	// с таймаутом Prober и не должны довольствоваться остатком
+	handshakeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	serverName := service.TLS.ServerName
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(service.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+		serverName = host
+	}
+
+	start := time.Now()
+	tlsConn, err := handshake(handshakeCtx, c.dialer, service.Address, service.TLS.StartTLS, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: service.TLS.SkipVerify,
+	})
+	latency := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	state := tlsConn.ConnectionState()
+	tlsConn.Close()
+	cancel()
+
+	logger.Debug("got tls handshake", "version", tls.VersionName(state.Version), "cipher", tls.CipherSuiteName(state.CipherSuite), "latency", latency)
+
+	checkInput := &domain.CheckInput{
+		Latency: latency,
+		TLS:     &state,
+	}
+
+	var result []domain.CheckResult
+	for _, rule := range service.Rules {
+		result = append(result, rule.Check(ctx, checkInput))
+	}
+
+	report := &domain.CheckReport{
+		Results: result,
+		Latency: latency,
+	}
+	if len(state.PeerCertificates) > 0 {
+		report.CertNotAfter = state.PeerCertificates[0].NotAfter
+	}
+
+	return report, nil
+}
+
+// handshake подключается к address, при необходимости выполняет STARTTLS и рукопожатие TLS.
+func handshake(ctx context.Context, dialer Dialer, address string, starttls string, config *tls.Config) (*tls.Conn, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed connect: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if starttls != "" {
+		if err := startTLS(conn, starttls); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed starttls %s: %w", starttls, err)
+		}
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed tls handshake: %w", err)
+	}
+
+	return tlsConn, nil
+}
@@ -0,0 +1,179 @@
+package tlscheck
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate сертификат httptest, выдан для example.com и 127.0.0.1
+func testCertificate(t *testing.T) tls.Certificate {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	return server.TLS.Certificates[0]
+}
+
+// serveStartTLS принимает одно соединение, проигрывает диалог протокола и переходит на TLS.
+func serveStartTLS(t *testing.T, dialog func(conn net.Conn, reader *bufio.Reader)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	cert := testCertificate(t)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if dialog != nil {
+			dialog(conn, bufio.NewReader(conn))
+		}
+
+		tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+	}()
+
+	return listener.Addr().String()
+}
+
+// deadlineRule запоминает, ограничен ли контекст, с которым вызвано правило.
+type deadlineRule struct {
+	hasDeadline bool
+}
+
+func (r *deadlineRule) Check(ctx context.Context, input *domain.CheckInput) domain.CheckResult {
+	_, r.hasDeadline = ctx.Deadline()
+	return domain.CheckResult{OK: domain.OK}
+}
+
+func TestTLSServiceChecker(t *testing.T) {
+	checker := NewChecker(&net.Dialer{}, 2*time.Second)
+
+	t.Run("таймаут рукопожатия не распространяется на правила", func(t *testing.T) {
+		address := serveStartTLS(t, nil)
+
+		rule := &deadlineRule{}
+		_, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			TLS:     domain.TLSEndpoint{SkipVerify: true},
+			Rules:   []domain.CheckRule{rule},
+		})
+		require.NoError(t, err)
+		assert.False(t, rule.hasDeadline)
+	})
+
+	t.Run("рукопожатие и правила по сертификату", func(t *testing.T) {
+		address := serveStartTLS(t, nil)
+
+		report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Type:    config.SERVICE_TYPE_TLS,
+			Address: address,
+			TLS:     domain.TLSEndpoint{SkipVerify: true},
+			Rules: []domain.CheckRule{
+				domain.NewSSLChecker(10, 5),
+				domain.NewLatencyRule(1000),
+			},
+		})
+		require.NoError(t, err)
+
+		assert.False(t, report.CertNotAfter.IsZero())
+		assert.Greater(t, report.Latency, time.Duration(0))
+		for _, result := range report.Results {
+			assert.Equal(t, domain.OK, result.OK, result.RuleType+": "+result.Message)
+		}
+	})
+
+	t.Run("без skip verify недоверенный сертификат — ошибка", func(t *testing.T) {
+		address := serveStartTLS(t, nil)
+
+		_, err := checker.ServiceCheck(t.Context(), &domain.Service{Address: address})
+		assert.ErrorContains(t, err, "failed tls handshake")
+	})
+
+	expect := func(reader *bufio.Reader, prefix string) bool {
+		line, err := reader.ReadString('\n')
+		return err == nil && strings.HasPrefix(line, prefix)
+	}
+
+	dialogs := map[string]func(conn net.Conn, reader *bufio.Reader){
+		config.STARTTLS_SMTP: func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("220-mail.example.ru ESMTP\r\n220 ready\r\n"))
+			if expect(reader, "EHLO") {
+				conn.Write([]byte("250-mail.example.ru\r\n250-PIPELINING\r\n250 STARTTLS\r\n"))
+			}
+			if expect(reader, "STARTTLS") {
+				conn.Write([]byte("220 go ahead\r\n"))
+			}
+		},
+		config.STARTTLS_FTP: func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("220 ftp ready\r\n"))
+			if expect(reader, "AUTH TLS") {
+				conn.Write([]byte("234 proceed\r\n"))
+			}
+		},
+		config.STARTTLS_IMAP: func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("* OK IMAP4rev1 ready\r\n"))
+			if expect(reader, "a1 STARTTLS") {
+				conn.Write([]byte("* CAPABILITY IMAP4rev1\r\na1 OK begin tls\r\n"))
+			}
+		},
+		config.STARTTLS_POP3: func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("+OK pop3 ready\r\n"))
+			if expect(reader, "STLS") {
+				conn.Write([]byte("+OK begin tls\r\n"))
+			}
+		},
+		config.STARTTLS_POSTGRES: func(conn net.Conn, reader *bufio.Reader) {
+			request := make([]byte, 8)
+			if _, err := reader.Read(request); err == nil {
+				conn.Write([]byte("S"))
+			}
+		},
+	}
+
+	for protocol, dialog := range dialogs {
+		t.Run("starttls "+protocol, func(t *testing.T) {
+			address := serveStartTLS(t, dialog)
+
+			report, err := checker.ServiceCheck(t.Context(), &domain.Service{
+				Address: address,
+				TLS:     domain.TLSEndpoint{StartTLS: protocol, SkipVerify: true},
+				Rules:   []domain.CheckRule{domain.NewSSLChecker(10, 5)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, domain.OK, report.Results[0].OK)
+		})
+	}
+
+	t.Run("сервер отказал в starttls", func(t *testing.T) {
+		address := serveStartTLS(t, func(conn net.Conn, reader *bufio.Reader) {
+			conn.Write([]byte("220 ready\r\n"))
+			if expect(reader, "EHLO") {
+				conn.Write([]byte("250 mail.example.ru\r\n"))
+			}
+			if expect(reader, "STARTTLS") {
+				conn.Write([]byte("454 TLS not available\r\n"))
+			}
+		})
+
+		_, err := checker.ServiceCheck(t.Context(), &domain.Service{
+			Address: address,
+			TLS:     domain.TLSEndpoint{StartTLS: config.STARTTLS_SMTP, SkipVerify: true},
+		})
+		assert.ErrorContains(t, err, "unexpected reply, expected 220: 454 TLS not available")
+	})
+}
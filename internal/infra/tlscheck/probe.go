@@ -0,0 +1,42 @@
+package tlscheck
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+// NewProber рукопожатия для проверки tls_policy, сертификат в них не проверяется.
+func NewProber(dialer Dialer, timeout time.Duration) domain.TLSProber {
+	return &Prober{
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+type Prober struct {
+	dialer  Dialer
+	timeout time.Duration
+}
+
+func (p *Prober) Probe(ctx context.Context, target domain.TLSProbeTarget, version uint16, cipherSuites []uint16) (*tls.ConnectionState, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	conn, err := handshake(ctx, p.dialer, target.Address, target.StartTLS, &tls.Config{
+		ServerName:         target.ServerName,
+		InsecureSkipVerify: true,
+		MinVersion:         version,
+		MaxVersion:         version,
+		CipherSuites:       cipherSuites,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	return &state, nil
+}
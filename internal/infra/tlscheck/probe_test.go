@@ -0,0 +1,77 @@
+package tlscheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProber(t *testing.T) {
+	server := httptest.NewUnstartedServer(nil)
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS11,
+		MaxVersion: tls.VersionTLS12,
+		// CBC с SHA256 Go относит к небезопасным
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	prober := NewProber(&net.Dialer{}, 2*time.Second)
+	target := domain.TLSProbeTarget{Address: server.Listener.Addr().String(), ServerName: "example.com"}
+
+	rule := domain.NewTLSPolicyRule(prober, target, tls.VersionTLS12, 0, map[string]domain.Severity{
+		config.TLS_POLICY_INSECURE_VERSION: domain.CRIT,
+		config.TLS_POLICY_WEAK_CIPHER:      domain.WARN,
+	})
+
+	got := rule.Check(t.Context(), &domain.CheckInput{})
+	assert.Equal(t, domain.CRIT, got.OK)
+	assert.Equal(t, "принимается TLS 1.1 (crit); "+
+		"принимается слабый шифр TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256 (TLS 1.2) (warn); "+
+		"принимаются TLS 1.1, TLS 1.2, шифр TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", got.Message)
+}
+
+func TestTLSCertificateRuleProbe(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	prober := NewProber(&net.Dialer{}, 2*time.Second)
+	severities := map[string]domain.Severity{}
+	for item := range config.TLSCertificateFindings {
+		severities[item] = domain.CRIT
+	}
+
+	check := func(serverName string, roots *x509.CertPool) domain.CheckResult {
+		target := domain.TLSProbeTarget{Address: server.Listener.Addr().String(), ServerName: serverName}
+		rule := domain.NewTLSCertificateRule(prober, target, domain.TLSCertificatePolicy{Roots: roots, Severities: severities})
+		return rule.Check(t.Context(), &domain.CheckInput{})
+	}
+
+	t.Run("сертификат из ca_file доверенный", func(t *testing.T) {
+		got := check("example.com", roots)
+		assert.Equal(t, domain.OK, got.OK, got.Message)
+	})
+
+	t.Run("имя проверяется по server_name", func(t *testing.T) {
+		got := check("mail.example.ru", roots)
+		assert.Equal(t, domain.CRIT, got.OK)
+		assert.Contains(t, got.Message, "сертификат не выдан для mail.example.ru")
+	})
+
+	t.Run("без ca_file сертификат самоподписанный", func(t *testing.T) {
+		got := check("example.com", nil)
+		assert.Equal(t, domain.CRIT, got.OK)
+		assert.Contains(t, got.Message, "самоподписанный")
+	})
+}
@@ -0,0 +1,143 @@
+package tlscheck
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/kias-hack/web-watcher/internal/config"
+)
+
+// postgresSSLRequest длина сообщения 8 и код запроса 80877103
+var postgresSSLRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// startTLS переводит открытое соединение в режим TLS по правилам протокола, после неё можно начинать рукопожатие.
+func startTLS(conn net.Conn, protocol string) error {
+	reader := bufio.NewReader(conn)
+
+	switch protocol {
+	case config.STARTTLS_SMTP:
+		return startTLSSMTP(conn, reader)
+	case config.STARTTLS_FTP:
+		if err := expectReply(reader, "220"); err != nil {
+			return err
+		}
+		return command(conn, reader, "AUTH TLS", "234")
+	case config.STARTTLS_IMAP:
+		if err := expectLine(reader, "* OK"); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+			return err
+		}
+		return expectTagged(reader, "a1", "a1 OK")
+	case config.STARTTLS_POP3:
+		if err := expectLine(reader, "+OK"); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(conn, "STLS\r\n"); err != nil {
+			return err
+		}
+		return expectLine(reader, "+OK")
+	case config.STARTTLS_POSTGRES:
+		if _, err := conn.Write(postgresSSLRequest); err != nil {
+			return err
+		}
+		answer, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if answer != 'S' {
+			return errors.New("server does not support ssl")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown starttls protocol: %s", protocol)
+}
+
+func startTLSSMTP(conn net.Conn, reader *bufio.Reader) error {
+	if err := expectReply(reader, "220"); err != nil {
+		return err
+	}
+
+	if err := command(conn, reader, "EHLO web-watcher", "250"); err != nil {
+		return err
+	}
+
+	return command(conn, reader, "STARTTLS", "220")
+}
+
+// command отправляет команду SMTP/FTP и ждёт ответ с кодом code.
+func command(conn net.Conn, reader *bufio.Reader, cmd string, code string) error {
+	if _, err := io.WriteString(conn, cmd+"\r\n"); err != nil {
+		return err
+	}
+
+	return expectReply(reader, code)
+}
+
+// expectReply читает ответ SMTP/FTP, в многострочном ответе после кода идёт "-", в последней строке — пробел.
+func expectReply(reader *bufio.Reader, code string) error {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return err
+		}
+
+		if len(line) < 4 || line[3] == '-' {
+			continue
+		}
+
+		if !strings.HasPrefix(line, code) {
+			return fmt.Errorf("unexpected reply, expected %s: %s", code, line)
+		}
+
+		return nil
+	}
+}
+
+func expectLine(reader *bufio.Reader, prefix string) error {
+	line, err := readLine(reader)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(line, prefix) {
+		return fmt.Errorf("unexpected reply, expected %s: %s", prefix, line)
+	}
+
+	return nil
+}
+
+// expectTagged пропускает непомеченные ответы IMAP до ответа на команду с тегом tag.
+func expectTagged(reader *bufio.Reader, tag string, prefix string) error {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(line, tag+" ") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, prefix) {
+			return fmt.Errorf("unexpected reply, expected %s: %s", prefix, line)
+		}
+
+		return nil
+	}
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed read reply: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DEFAULT_HISTORY_PERIOD за какой период отдаётся история без параметра from
+const DEFAULT_HISTORY_PERIOD = 24 * time.Hour
+
+type historyView struct {
+	Records []historyRecordView `json:"records"`
+}
+
+type historyRecordView struct {
+	CheckedAt  time.Time    `json:"checked_at"`
+	Severity   string       `json:"severity"`
+	Latency    string       `json:"latency"`
+	StatusCode int          `json:"status_code,omitempty"`
+	Results    []resultView `json:"results"`
+}
+
+// history отдаёт записи истории сервиса за [from, to], параметры в RFC 3339.
+// По умолчанию to — текущее время, from — to минус DEFAULT_HISTORY_PERIOD.
+func (h *statusHandler) historyList(w http.ResponseWriter, r *http.Request) {
+	if h.results == nil {
+		writeJSON(w, http.StatusNotFound, errorView{Error: "history is disabled"})
+		return
+	}
+
+	name := r.PathValue("name")
+	if !h.serviceExists(name) {
+		writeJSON(w, http.StatusNotFound, errorView{Error: "service not found"})
+		return
+	}
+
+	to, err := parseTimeParam(r, "to", time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorView{Error: err.Error()})
+		return
+	}
+
+	from, err := parseTimeParam(r, "from", to.Add(-DEFAULT_HISTORY_PERIOD))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorView{Error: err.Error()})
+		return
+	}
+
+	records, err := h.results.Query(r.Context(), name, from, to)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorView{Error: "failed read history"})
+		return
+	}
+
+	result := historyView{Records: make([]historyRecordView, 0, len(records))}
+	for _, record := range records {
+		result.Records = append(result.Records, historyRecordView{
+			CheckedAt:  record.CheckedAt,
+			Severity:   record.Severity.String(),
+			Latency:    record.Latency.String(),
+			StatusCode: record.StatusCode,
+			Results:    newResultViews(record.Results),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *statusHandler) serviceExists(name string) bool {
+	for _, snapshot := range h.provider.Snapshot() {
+		if snapshot.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseTimeParam(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s, expected RFC 3339 time", name)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"formatTime": func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return t.Local().Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>web-watcher</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+.ok { color: #1a7f37; }
+.warn { color: #9a6700; }
+.crit { color: #cf222e; font-weight: bold; }
+.unknown { color: #777; }
+ul { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>Состояние сервисов</h1>
+<table>
+<tr><th>Сервис</th><th>Состояние</th><th>Проблемы</th><th>Последняя проверка</th><th>Последнее уведомление</th></tr>
+{{range .}}
+<tr>
+<td>{{if .URL}}<a href="{{.URL}}">{{.Name}}</a>{{else}}{{.Name}} <small>{{.Address}}</small>{{end}}</td>
+<td class="{{.Severity}}">{{.Severity}}{{if .Flapping}} (флаппинг {{printf "%.0f" .FlapScore}}%){{end}}{{if .Pending}}<br><small>ожидает подтверждения: {{.Pending.Severity}} × {{.Pending.Count}}</small>{{end}}</td>
+<td>{{with .Problems}}<ul>{{range .}}<li class="{{.Severity}}">{{.RuleType}}: {{.Message}}</li>{{end}}</ul>{{end}}</td>
+<td>{{formatTime .CheckedAt}}</td>
+<td>{{formatTime .NotifiedAt}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+type pageRow struct {
+	serviceView
+	Problems []resultView
+}
+
+func (h *statusHandler) page(w http.ResponseWriter, r *http.Request) {
+	var rows []pageRow
+	for _, snapshot := range h.provider.Snapshot() {
+		view := newServiceView(snapshot)
+
+		row := pageRow{serviceView: view}
+		for _, result := range view.Results {
+			if result.Severity != domain.OK.String() {
+				row.Problems = append(row.Problems, result)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := pageTemplate.Execute(w, rows); err != nil {
+		slog.Error("failed render status page", "component", "status_handler", "err", err)
+	}
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+)
+
+const SEVERITY_UNKNOWN = "unknown"
+
+// StatusProvider источник состояний сервисов, его реализует watchdog.
+type StatusProvider interface {
+	Snapshot() []domain.ServiceSnapshot
+}
+
+// NewStatusHandler отдаёт html-страницу состояния на / и json api на /api/v1/services.
+// results может быть nil — тогда история проверок недоступна.
+func NewStatusHandler(provider StatusProvider, results domain.ResultStore) http.Handler {
+	h := &statusHandler{provider: provider, results: results}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.page)
+	mux.HandleFunc("GET /api/v1/services", h.list)
+	mux.HandleFunc("GET /api/v1/services/{name}", h.get)
+	mux.HandleFunc("GET /api/v1/services/{name}/history", h.historyList)
+
+	return mux
+}
+
+type statusHandler struct {
+	provider StatusProvider
+	results  domain.ResultStore
+}
+
+type serviceView struct {
+	Name       string       `json:"name"`
+	URL        string       `json:"url"`
+	Address    string       `json:"address,omitempty"`
+	Interval   string       `json:"interval"`
+	Severity   string       `json:"severity"`
+	CheckedAt  *time.Time   `json:"checked_at"`
+	NotifiedAt *time.Time   `json:"notified_at"`
+	Pending    *pendingView `json:"pending,omitempty"`
+	Flapping   bool         `json:"flapping"`
+	FlapScore  float64      `json:"flap_score"`
+	Results    []resultView `json:"results"`
+}
+
+type pendingView struct {
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+}
+
+type resultView struct {
+	RuleType string `json:"rule_type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type serviceListView struct {
+	Services []serviceView `json:"services"`
+}
+
+type errorView struct {
+	Error string `json:"error"`
+}
+
+func (h *statusHandler) list(w http.ResponseWriter, r *http.Request) {
+	snapshots := h.provider.Snapshot()
+
+	result := serviceListView{Services: make([]serviceView, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		result.Services = append(result.Services, newServiceView(snapshot))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *statusHandler) get(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	for _, snapshot := range h.provider.Snapshot() {
+		if snapshot.Name == name {
+			writeJSON(w, http.StatusOK, newServiceView(snapshot))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusNotFound, errorView{Error: "service not found"})
+}
+
+func newServiceView(snapshot domain.ServiceSnapshot) serviceView {
+	view := serviceView{
+		Name:     snapshot.Name,
+		URL:      snapshot.URL,
+		Address:  snapshot.Address,
+		Interval: snapshot.Interval.String(),
+		Severity: SEVERITY_UNKNOWN,
+		Results:  []resultView{},
+	}
+
+	if !snapshot.Checked {
+		return view
+	}
+
+	status := snapshot.Status
+
+	view.Severity = domain.GetMaxSeverity(status.CheckResults).String()
+	view.CheckedAt = timeOrNil(status.LastChecked)
+	view.NotifiedAt = timeOrNil(status.LastSent)
+	view.Flapping = status.Flapping
+	view.FlapScore = status.FlapScore
+
+	if status.PendingCount > 0 {
+		view.Pending = &pendingView{Severity: status.PendingSeverity.String(), Count: status.PendingCount}
+	}
+
+	view.Results = newResultViews(status.CheckResults)
+
+	return view
+}
+
+func newResultViews(results []domain.CheckResult) []resultView {
+	views := make([]resultView, 0, len(results))
+	for _, result := range results {
+		views = append(views, resultView{
+			RuleType: result.RuleType,
+			Severity: result.OK.String(),
+			Message:  result.Message,
+		})
+	}
+
+	return views
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed write response", "component", "status_handler", "err", err)
+	}
+}
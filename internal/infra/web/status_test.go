@@ -0,0 +1,180 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kias-hack/web-watcher/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProvider []domain.ServiceSnapshot
+
+func (p staticProvider) Snapshot() []domain.ServiceSnapshot {
+	return p
+}
+
+func TestStatusHandler(t *testing.T) {
+	checkedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	handler := NewStatusHandler(staticProvider{
+		{
+			Name:     "example.ru",
+			URL:      "https://example.ru/",
+			Interval: time.Minute,
+			Checked:  true,
+			Status: domain.ServiceStatus{
+				LastChecked: checkedAt,
+				CheckResults: []domain.CheckResult{
+					{RuleType: "status_code", OK: domain.CRIT, Message: "ожидается статус 200, получен 500"},
+					{RuleType: "max_latency", OK: domain.OK},
+				},
+			},
+		},
+		{Name: "<new>", URL: "https://example.com/", Interval: time.Minute},
+	}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("список сервисов", func(t *testing.T) {
+		rec := get("/api/v1/services")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var got map[string][]map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got["services"], 2)
+
+		assert.Equal(t, "crit", got["services"][0]["severity"])
+		assert.Equal(t, "2026-01-02T03:04:05Z", got["services"][0]["checked_at"])
+		assert.Nil(t, got["services"][0]["notified_at"])
+		assert.Len(t, got["services"][0]["results"], 2)
+
+		assert.Equal(t, "unknown", got["services"][1]["severity"])
+		assert.Nil(t, got["services"][1]["checked_at"])
+	})
+
+	t.Run("один сервис", func(t *testing.T) {
+		rec := get("/api/v1/services/example.ru")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, "example.ru", got["name"])
+		assert.Equal(t, "1m0s", got["interval"])
+	})
+
+	t.Run("неизвестный сервис", func(t *testing.T) {
+		rec := get("/api/v1/services/missing")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"error":"service not found"}`, rec.Body.String())
+	})
+
+	t.Run("html-страница", func(t *testing.T) {
+		rec := get("/")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		body := rec.Body.String()
+		assert.Contains(t, body, "status_code: ожидается статус 200, получен 500")
+		assert.NotContains(t, body, "max_latency")
+		assert.Contains(t, body, "&lt;new&gt;")
+	})
+}
+
+// memoryResults история в памяти, Query фильтрует записи по интервалу как файловое хранилище.
+type memoryResults []domain.HistoryRecord
+
+func (r memoryResults) Append(ctx context.Context, record domain.HistoryRecord) error {
+	return nil
+}
+
+func (r memoryResults) Query(ctx context.Context, serviceName string, from, to time.Time) ([]domain.HistoryRecord, error) {
+	var result []domain.HistoryRecord
+	for _, record := range r {
+		if record.ServiceName == serviceName && !record.CheckedAt.Before(from) && !record.CheckedAt.After(to) {
+			result = append(result, record)
+		}
+	}
+
+	return result, nil
+}
+
+func (r memoryResults) Prune(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+func TestStatusHandler_History(t *testing.T) {
+	checkedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	provider := staticProvider{{Name: "example.ru", URL: "https://example.ru/", Interval: time.Minute}}
+
+	handler := NewStatusHandler(provider, memoryResults{
+		{
+			ServiceName: "example.ru",
+			CheckedAt:   checkedAt,
+			Latency:     150 * time.Millisecond,
+			StatusCode:  500,
+			Severity:    domain.CRIT,
+			Results:     []domain.CheckResult{{RuleType: "status_code", OK: domain.CRIT, Message: "ожидается статус 200, получен 500"}},
+		},
+		{
+			ServiceName: "example.ru",
+			CheckedAt:   checkedAt.Add(-48 * time.Hour),
+			Severity:    domain.OK,
+		},
+	})
+
+	get := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("записи за период", func(t *testing.T) {
+		rec := get(handler, "/api/v1/services/example.ru/history?from=2026-01-02T00:00:00Z&to=2026-01-03T00:00:00Z")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		assert.JSONEq(t, `{"records": [{
+			"checked_at": "2026-01-02T03:04:05Z",
+			"severity": "crit",
+			"latency": "150ms",
+			"status_code": 500,
+			"results": [{"rule_type": "status_code", "severity": "crit", "message": "ожидается статус 200, получен 500"}]
+		}]}`, rec.Body.String())
+	})
+
+	t.Run("from по умолчанию — сутки до to", func(t *testing.T) {
+		rec := get(handler, "/api/v1/services/example.ru/history?to=2026-01-01T03:04:05Z")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var got historyView
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got.Records, 1)
+		assert.Equal(t, "ok", got.Records[0].Severity)
+	})
+
+	t.Run("неверное время", func(t *testing.T) {
+		rec := get(handler, "/api/v1/services/example.ru/history?from=yesterday")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid from, expected RFC 3339 time"}`, rec.Body.String())
+	})
+
+	t.Run("неизвестный сервис", func(t *testing.T) {
+		rec := get(handler, "/api/v1/services/missing/history")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("история выключена", func(t *testing.T) {
+		rec := get(NewStatusHandler(provider, nil), "/api/v1/services/example.ru/history")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"error":"history is disabled"}`, rec.Body.String())
+	})
+}
@@ -4,18 +4,36 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"reflect"
 	"slices"
 	"sync"
 	"time"
 
+	"github.com/kias-hack/web-watcher/internal/delivery"
 	"github.com/kias-hack/web-watcher/internal/domain"
 )
 
+// Metrics получает результаты проверок и подтверждённые состояния сервисов.
+type Metrics interface {
+	// CheckCompleted вызывается после каждой проверки, err — ошибка запроса к сервису.
+	CheckCompleted(serviceName string, report *domain.CheckReport, err error)
+	ServiceState(serviceName string, severity domain.Severity)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CheckCompleted(serviceName string, report *domain.CheckReport, err error) {}
+func (nopMetrics) ServiceState(serviceName string, severity domain.Severity)                {}
+
 type Watchdog struct {
 	services        []*domain.Service
-	serviceStatuses map[*domain.Service]*domain.ServiceStatus
+	serviceStatuses map[string]*domain.ServiceStatus
 	serviceChecker  domain.ServiceChecker
 	alertRules      []domain.RoutedNotifier
+	dispatcher      *delivery.Dispatcher
+	stateStore      domain.StateStore
+	resultStore     domain.ResultStore
+	metrics         Metrics
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -23,13 +41,23 @@ type Watchdog struct {
 	mu     sync.Mutex
 }
 
-func NewWatchdog(services []*domain.Service, serviceChecker domain.ServiceChecker, alertRules []domain.RoutedNotifier) *Watchdog {
+// NewWatchdog создаёт watchdog. stateStore может быть nil — тогда состояние сервисов не переживает перезапуск,
+// resultStore может быть nil — тогда история проверок не пишется, metrics может быть nil.
+func NewWatchdog(services []*domain.Service, serviceChecker domain.ServiceChecker, alertRules []domain.RoutedNotifier, dispatcher *delivery.Dispatcher, stateStore domain.StateStore, resultStore domain.ResultStore, metrics Metrics) *Watchdog {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+
 	return &Watchdog{
 		services:        services,
-		serviceStatuses: make(map[*domain.Service]*domain.ServiceStatus),
+		serviceStatuses: make(map[string]*domain.ServiceStatus),
 		serviceChecker:  serviceChecker,
 		mu:              sync.Mutex{},
 		alertRules:      alertRules,
+		dispatcher:      dispatcher,
+		stateStore:      stateStore,
+		resultStore:     resultStore,
+		metrics:         metrics,
 	}
 }
 
@@ -38,11 +66,17 @@ func (w *Watchdog) Start() error {
 		return fmt.Errorf("watchdog already started")
 	}
 
+	if err := w.loadState(); err != nil {
+		return err
+	}
+
 	w.wg = &sync.WaitGroup{}
 	ctx, cancel := context.WithCancel(context.Background())
 	w.ctx = ctx
 	w.cancel = cancel
 
+	w.dispatcher.Start()
+
 	for _, service := range w.services {
 		w.wg.Add(1)
 		go w.worker(service)
@@ -64,34 +98,71 @@ func (w *Watchdog) Stop(ctx context.Context) error {
 		close(exit)
 	}()
 
+	var stopErr error
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		stopErr = ctx.Err()
 	case <-exit:
-		w.cancel = nil
-		w.ctx = nil
-		return nil
 	}
+
+	// новых событий от проверок больше не будет — дожидаемся отправки уже поставленных в очередь,
+	// а если ctx истёк, диспетчер отложит их в outbox
+	if err := w.dispatcher.Stop(ctx); err != nil {
+		return fmt.Errorf("failed drain notifications: %w", err)
+	}
+
+	if stopErr != nil {
+		return stopErr
+	}
+
+	w.cancel = nil
+	w.ctx = nil
+
+	return nil
 }
 
-func (w *Watchdog) handleServiceResult(service *domain.Service, result []domain.CheckResult) {
+// handleServiceResult обновляет состояние сервиса и рассылает уведомления.
+// Возвращает true, если изменение состояния ещё не подтверждено и проверку нужно повторить через RetryInterval.
+func (w *Watchdog) handleServiceResult(service *domain.Service, result []domain.CheckResult) bool {
 	logger := slog.With("component", "watchdog", "op", "handleServiceResult", "service", service.Name)
 
 	w.mu.Lock()
-	serviceState, ok := w.serviceStatuses[service]
+	serviceState, ok := w.serviceStatuses[service.Name]
 	if !ok {
 		serviceState = &domain.ServiceStatus{}
-		w.serviceStatuses[service] = serviceState
+		w.serviceStatuses[service.Name] = serviceState
 	}
-	oldState := *serviceState
+	oldState := snapshotState(serviceState)
+	serviceState.LastChecked = time.Now()
+
+	result, pending := domain.ConfirmResults(service.Confirm, serviceState, result)
+	if pending {
+		snapshot := snapshotState(serviceState)
+		w.mu.Unlock()
+		w.saveState(logger, service.Name, oldState, snapshot)
+		w.metrics.ServiceState(service.Name, domain.GetMaxSeverity(snapshot.CheckResults))
+		logger.Info("state change not confirmed yet", "pending_count", snapshot.PendingCount, "pending_severity", snapshot.PendingSeverity)
+		return true
+	}
+
 	serviceState.CheckResults = result
 
 	logger.Debug("got service status")
 
+	kind := domain.EventAlert
+	switch domain.DetectFlapping(service.Flap, serviceState, domain.GetMaxSeverity(result)) {
+	case domain.FlapStarted:
+		logger.Warn("service started flapping", "flap_score", serviceState.FlapScore)
+		kind = domain.EventFlappingStarted
+	case domain.FlapStopped:
+		logger.Info("service stopped flapping", "flap_score", serviceState.FlapScore)
+		kind = domain.EventFlappingStopped
+	}
+
 	now := time.Now()
 
 	var foundNotifier bool = false
-	var toSend []domain.Notifier
+	var toSend []domain.RoutedNotifier
 	for _, rule := range w.alertRules {
 		logger.Debug("check rule", "rule", rule.Rule)
 
@@ -103,8 +174,14 @@ func (w *Watchdog) handleServiceResult(service *domain.Service, result []domain.
 
 		foundNotifier = true
 
-		if domain.CanSendNotify(rule.Rule, result, oldState, now) {
-			toSend = append(toSend, rule.Notifier)
+		switch {
+		case kind != domain.EventAlert:
+			// о начале и конце флаппинга сообщаем всем получателям сервиса
+			toSend = append(toSend, rule)
+		case serviceState.Flapping:
+			logger.Debug("notification suppressed, service is flapping", "notifier", rule.Name)
+		case domain.CanSendNotify(rule.Rule, result, oldState, now):
+			toSend = append(toSend, rule)
 		}
 	}
 
@@ -112,21 +189,116 @@ func (w *Watchdog) handleServiceResult(service *domain.Service, result []domain.
 		serviceState.LastSent = now
 	}
 
+	flapScore := serviceState.FlapScore
+	snapshot := snapshotState(serviceState)
+
 	w.mu.Unlock()
 
+	w.saveState(logger, service.Name, oldState, snapshot)
+	w.metrics.ServiceState(service.Name, domain.GetMaxSeverity(result))
+
 	event := &domain.AlertEvent{
+		Kind:        kind,
+		FlapScore:   flapScore,
 		ServiceName: service.Name,
 		Status:      domain.GetMaxSeverity(result),
 		Results:     result,
+		CheckedAt:   now,
 	}
-	for _, notifier := range toSend {
-		logger.Debug("send notification")
-		notifier.Notify(w.ctx, event)
+	for _, rule := range toSend {
+		logger.Debug("send notification", "notifier", rule.Name)
+		if err := w.dispatcher.Dispatch(rule.Name, event); err != nil {
+			logger.Error("failed dispatch notification", "notifier", rule.Name, "err", err)
+		}
 	}
 
 	if !foundNotifier {
 		logger.Warn("for service not found notifications")
 	}
+
+	return false
+}
+
+// Snapshot возвращает копии состояний сервисов в порядке из конфига.
+func (w *Watchdog) Snapshot() []domain.ServiceSnapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := make([]domain.ServiceSnapshot, 0, len(w.services))
+	for _, service := range w.services {
+		snapshot := domain.ServiceSnapshot{
+			Name:     service.Name,
+			URL:      service.URL,
+			Address:  service.Address,
+			Interval: service.Interval,
+		}
+
+		if state, ok := w.serviceStatuses[service.Name]; ok {
+			snapshot.Checked = true
+			snapshot.Status = snapshotState(state)
+		}
+
+		result = append(result, snapshot)
+	}
+
+	return result
+}
+
+// loadState восстанавливает состояния сервисов из хранилища. Состояния сервисов, которых больше нет в конфиге, игнорируются.
+func (w *Watchdog) loadState() error {
+	if w.stateStore == nil {
+		return nil
+	}
+
+	states, err := w.stateStore.Load(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed load service states: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, service := range w.services {
+		state, ok := states[service.Name]
+		if !ok {
+			continue
+		}
+
+		w.serviceStatuses[service.Name] = &state
+	}
+
+	slog.Info("service states loaded", "component", "watchdog", "count", len(w.serviceStatuses))
+
+	return nil
+}
+
+// saveState сохраняет состояние, только если оно изменилось, иначе файл состояния переписывался бы
+// на каждой проверке каждого сервиса. LastChecked не учитывается: после перезапуска он будет от последнего изменения.
+func (w *Watchdog) saveState(logger *slog.Logger, serviceName string, oldState domain.ServiceStatus, state domain.ServiceStatus) {
+	if w.stateStore == nil || !stateChanged(oldState, state) {
+		return
+	}
+
+	// сохраняем и во время остановки, иначе последний результат проверки потеряется
+	if err := w.stateStore.Save(context.WithoutCancel(w.ctx), serviceName, state); err != nil {
+		logger.Error("failed save service state", "err", err)
+	}
+}
+
+func stateChanged(oldState domain.ServiceStatus, state domain.ServiceStatus) bool {
+	oldState.LastChecked = time.Time{}
+	state.LastChecked = time.Time{}
+
+	return !reflect.DeepEqual(oldState, state)
+}
+
+// snapshotState копирует состояние, чтобы сохранять его без блокировки.
+func snapshotState(state *domain.ServiceStatus) domain.ServiceStatus {
+	snapshot := *state
+	snapshot.CheckResults = slices.Clone(state.CheckResults)
+	snapshot.StateHistory = slices.Clone(state.StateHistory)
+
+	return snapshot
 }
 
 func (w *Watchdog) worker(service *domain.Service) {
@@ -134,30 +306,63 @@ func (w *Watchdog) worker(service *domain.Service) {
 
 	logger := slog.With("component", "watchdog_worker", "service_name", service.Name)
 
-	ticker := time.NewTicker(service.Interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(service.Interval)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-w.ctx.Done():
 			logger.Info("stopping job")
 			return
-		case <-ticker.C:
-			results, err := w.serviceChecker.ServiceCheck(w.ctx, service)
-			if err != nil {
-				logger.Error("error occured when service check", "err", err)
-
-				w.handleServiceResult(service, []domain.CheckResult{
-					{
-						RuleType: "available",
-						OK:       domain.CRIT,
-						Message:  fmt.Sprintf("ошибка запроса к сервису: %s", err.Error()),
-					},
-				})
-				continue
+		case <-timer.C:
+			next := service.Interval
+			if w.checkService(logger, service) {
+				next = service.Confirm.RetryInterval
 			}
 
-			w.handleServiceResult(service, results)
+			timer.Reset(next)
 		}
 	}
 }
+
+func (w *Watchdog) checkService(logger *slog.Logger, service *domain.Service) bool {
+	checkedAt := time.Now()
+
+	report, err := w.serviceChecker.ServiceCheck(w.ctx, service)
+	if err != nil {
+		logger.Error("error occured when service check", "err", err)
+
+		report = &domain.CheckReport{
+			Results: []domain.CheckResult{
+				{
+					RuleType: "available",
+					OK:       domain.CRIT,
+					Message:  fmt.Sprintf("ошибка запроса к сервису: %s", err.Error()),
+				},
+			},
+		}
+	}
+
+	w.metrics.CheckCompleted(service.Name, report, err)
+	w.appendHistory(logger, service, checkedAt, report)
+
+	return w.handleServiceResult(service, report.Results)
+}
+
+func (w *Watchdog) appendHistory(logger *slog.Logger, service *domain.Service, checkedAt time.Time, report *domain.CheckReport) {
+	if w.resultStore == nil {
+		return
+	}
+
+	err := w.resultStore.Append(context.WithoutCancel(w.ctx), domain.HistoryRecord{
+		ServiceName: service.Name,
+		CheckedAt:   checkedAt,
+		Latency:     report.Latency,
+		StatusCode:  report.StatusCode,
+		Severity:    domain.GetMaxSeverity(report.Results),
+		Results:     report.Results,
+	})
+	if err != nil {
+		logger.Error("failed append check history", "err", err)
+	}
+}